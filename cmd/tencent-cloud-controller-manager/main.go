@@ -7,7 +7,7 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
-	_ "github.com/weimob-tech/cloud-provider-tencent/pkg/tencentcloud"
+	"github.com/weimob-tech/cloud-provider-tencent/pkg/tencentcloud"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apiserver/pkg/util/term"
 	cliflag "k8s.io/component-base/cli/flag"
@@ -59,6 +59,12 @@ the cloud specific control loops shipped with Kubernetes.`,
 				os.Exit(1)
 			}
 
+			// the cloud provider checks the pod CIDRs do not overlap the vpc and owns the routes of the cluster
+			tencentcloud.RegisterCloudProvider(tencentcloud.ClusterOptions{
+				ClusterName: c.ComponentConfig.KubeCloudShared.ClusterName,
+				ClusterCIDR: c.ComponentConfig.KubeCloudShared.ClusterCIDR,
+			})
+
 			if err := app.Run(c.Complete(), wait.NeverStop); err != nil {
 				fmt.Fprintf(os.Stderr, "%v\n", err)
 				os.Exit(1)
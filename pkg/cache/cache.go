@@ -1,58 +1,202 @@
 package cache
 
 import (
+	"container/list"
+	"strings"
+	"sync"
 	"time"
 
-	"k8s.io/client-go/tools/cache"
+	"k8s.io/apimachinery/pkg/util/clock"
 )
 
-// TencentCloudCacheEntry is the internal structure stores inside TTLStore.
-type TencentCloudCacheEntry struct {
-	Key  string
-	Data interface{}
+// Options configures a Cache.
+type Options struct {
+	// Name identifies the cache in metrics.
+	Name string
+	// TTL is the lifetime of an entry whose key matches no namespace.
+	TTL time.Duration
+	// NamespaceTTLs overrides TTL for keys starting with the namespace, the longest namespace wins.
+	NamespaceTTLs map[string]time.Duration
+	// MaxEntries bounds the cache size, the least recently used entry is evicted first. 0 means unbounded.
+	MaxEntries int
+	// Clock is the time source, defaults to the real clock.
+	Clock clock.PassiveClock
 }
 
-// TTLCache is a cache with TTL.
-type TTLCache struct {
-	Store cache.Store
-	TTL   time.Duration
+// entry is the internal structure stores inside Cache.
+type entry[V any] struct {
+	key       string
+	value     V
+	expiresAt time.Time
+	// version is the version of the cache the entry was set at
+	version uint64
 }
 
-// NewTTLCache creates a new TTLCache.
-func NewTTLCache(ttl time.Duration) *TTLCache {
-	return &TTLCache{
-		Store: cache.NewTTLStore(cacheKeyFunc, ttl),
-		TTL:   ttl,
+// Cache is a typed cache with TTL and LRU eviction.
+type Cache[V any] struct {
+	name          string
+	ttl           time.Duration
+	namespaceTTLs map[string]time.Duration
+	maxEntries    int
+	clock         clock.PassiveClock
+
+	mu      sync.Mutex
+	lru     *list.List
+	entries map[string]*list.Element
+	// version is incremented by every set
+	version uint64
+}
+
+// New creates a new Cache.
+func New[V any](opts Options) *Cache[V] {
+	if opts.Clock == nil {
+		opts.Clock = clock.RealClock{}
+	}
+	return &Cache[V]{
+		name:          opts.Name,
+		ttl:           opts.TTL,
+		namespaceTTLs: opts.NamespaceTTLs,
+		maxEntries:    opts.MaxEntries,
+		clock:         opts.Clock,
+		lru:           list.New(),
+		entries:       make(map[string]*list.Element),
 	}
 }
 
-// TencentCloudCacheEntry defines the key function required in TTLStore.
-func cacheKeyFunc(obj interface{}) (string, error) {
-	return obj.(*TencentCloudCacheEntry).Key, nil
+// ttlFor returns the TTL of the key's namespace.
+func (c *Cache[V]) ttlFor(key string) time.Duration {
+	ttl, matched := c.ttl, ""
+	for namespace, namespaceTTL := range c.namespaceTTLs {
+		if strings.HasPrefix(key, namespace) && len(namespace) > len(matched) {
+			ttl, matched = namespaceTTL, namespace
+		}
+	}
+	return ttl
 }
 
-// Set sets the data cache for the key.
-func (t *TTLCache) Set(key string, data interface{}) {
-	_ = t.Store.Add(&TencentCloudCacheEntry{
-		Key:  key,
-		Data: data,
-	})
+// Set sets the data cache for the key with the TTL of its namespace.
+func (c *Cache[V]) Set(key string, value V) {
+	c.SetWithTTL(key, value, c.ttlFor(key))
+}
+
+// SetWithTTL sets the data cache for the key with an explicit TTL.
+func (c *Cache[V]) SetWithTTL(key string, value V, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := c.clock.Now().Add(ttl)
+	c.version++
+	if element, ok := c.entries[key]; ok {
+		e := element.Value.(*entry[V])
+		e.value, e.expiresAt, e.version = value, expiresAt, c.version
+		c.lru.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.lru.PushFront(&entry[V]{key: key, value: value, expiresAt: expiresAt, version: c.version})
+	for c.maxEntries > 0 && c.lru.Len() > c.maxEntries {
+		c.removeElement(c.lru.Back())
+		evictionsTotal.WithLabelValues(c.name, evictionReasonCapacity).Inc()
+	}
 }
 
 // Get get the cache data for the key.
-func (t *TTLCache) Get(key string) (interface{}, bool) {
-	item, exists, err := t.Store.GetByKey(key)
-	if err != nil || exists == false {
-		return nil, false
+func (c *Cache[V]) Get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+	element, ok := c.entries[key]
+	if !ok {
+		missesTotal.WithLabelValues(c.name).Inc()
+		return zero, false
 	}
-	return item.(*TencentCloudCacheEntry).Data, true
+	e := element.Value.(*entry[V])
+	if !c.clock.Now().Before(e.expiresAt) {
+		c.removeElement(element)
+		evictionsTotal.WithLabelValues(c.name, evictionReasonExpired).Inc()
+		missesTotal.WithLabelValues(c.name).Inc()
+		return zero, false
+	}
+
+	c.lru.MoveToFront(element)
+	hitsTotal.WithLabelValues(c.name).Inc()
+	return e.value, true
 }
 
-// Delete delete cache data for the key.
-func (t *TTLCache) Delete(key string) bool {
-	err := t.Store.Delete(key)
-	if err != nil {
+// Delete delete cache data for the key, returns whether the key was cached.
+func (c *Cache[V]) Delete(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
 		return false
 	}
+	c.removeElement(element)
 	return true
 }
+
+// Version returns the current version of the cache, every set after the call gets a greater one.
+func (c *Cache[V]) Version() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.version
+}
+
+// DeleteIfSetBefore deletes the entry of key when it was last set before Version returned version,
+// returns whether the key was deleted.
+func (c *Cache[V]) DeleteIfSetBefore(key string, version uint64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok || element.Value.(*entry[V]).version > version {
+		return false
+	}
+	c.removeElement(element)
+	return true
+}
+
+// Invalidate deletes every key starting with prefix, returns the number of deleted keys.
+func (c *Cache[V]) Invalidate(prefix string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	count := 0
+	for key, element := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			c.removeElement(element)
+			count++
+		}
+	}
+	return count
+}
+
+// Snapshot returns a copy of every unexpired entry.
+func (c *Cache[V]) Snapshot() map[string]V {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.clock.Now()
+	snapshot := make(map[string]V, len(c.entries))
+	for key, element := range c.entries {
+		if e := element.Value.(*entry[V]); now.Before(e.expiresAt) {
+			snapshot[key] = e.value
+		}
+	}
+	return snapshot
+}
+
+// Len returns the number of entries, expired ones not yet evicted included.
+func (c *Cache[V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// removeElement must be called with c.mu held.
+func (c *Cache[V]) removeElement(element *list.Element) {
+	c.lru.Remove(element)
+	delete(c.entries, element.Value.(*entry[V]).key)
+}
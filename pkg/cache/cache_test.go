@@ -1,26 +1,165 @@
 package cache
 
 import (
-	"log"
+	"reflect"
 	"testing"
 	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/component-base/metrics/testutil"
 )
 
-func TestCache(t *testing.T) {
-	key := "test"
-	value := "test_value"
-	c := NewTTLCache(5 * time.Second)
-	c.Set(key, value)
+func counterValue(t *testing.T, name string, values ...string) float64 {
+	t.Helper()
+	var value float64
+	var err error
+	switch name {
+	case "hits":
+		value, err = testutil.GetCounterMetricValue(hitsTotal.WithLabelValues(values...))
+	case "misses":
+		value, err = testutil.GetCounterMetricValue(missesTotal.WithLabelValues(values...))
+	case "evictions":
+		value, err = testutil.GetCounterMetricValue(evictionsTotal.WithLabelValues(values...))
+	}
+	if err != nil {
+		t.Fatalf("read %s counter: %v", name, err)
+	}
+	return value
+}
+
+func TestCacheTTL(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	c := New[string](Options{Name: "test_ttl", TTL: 5 * time.Second, Clock: fakeClock})
+	c.Set("key", "value")
+
+	fakeClock.Step(4 * time.Second)
+	if value, ok := c.Get("key"); !ok || value != "value" {
+		t.Errorf("expected value before TTL, got %q, %v", value, ok)
+	}
+
+	fakeClock.Step(time.Second)
+	if _, ok := c.Get("key"); ok {
+		t.Errorf("expected entry to expire after TTL")
+	}
+	if c.Len() != 0 {
+		t.Errorf("expected expired entry to be evicted, len %d", c.Len())
+	}
+
+	if hits := counterValue(t, "hits", "test_ttl"); hits != 1 {
+		t.Errorf("hits = %v, want 1", hits)
+	}
+	if misses := counterValue(t, "misses", "test_ttl"); misses != 1 {
+		t.Errorf("misses = %v, want 1", misses)
+	}
+	if evictions := counterValue(t, "evictions", "test_ttl", evictionReasonExpired); evictions != 1 {
+		t.Errorf("expired evictions = %v, want 1", evictions)
+	}
+}
+
+func TestCacheNamespaceTTL(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	c := New[int](Options{
+		Name:          "test_namespace",
+		TTL:           time.Minute,
+		NamespaceTTLs: map[string]time.Duration{"short_": time.Second, "short_longer_": time.Hour},
+		Clock:         fakeClock,
+	})
+	c.Set("short_a", 1)
+	c.Set("short_longer_a", 2)
+	c.Set("default_a", 3)
+	c.SetWithTTL("explicit_a", 4, 2*time.Second)
+
+	fakeClock.Step(time.Second)
+	if _, ok := c.Get("short_a"); ok {
+		t.Errorf("expected short_a to expire with its namespace TTL")
+	}
+	if _, ok := c.Get("explicit_a"); !ok {
+		t.Errorf("expected explicit_a to live for its explicit TTL")
+	}
+
+	fakeClock.Step(time.Minute)
+	if _, ok := c.Get("default_a"); ok {
+		t.Errorf("expected default_a to expire with the default TTL")
+	}
+	if _, ok := c.Get("short_longer_a"); !ok {
+		t.Errorf("expected the longest namespace TTL to win")
+	}
+}
+
+func TestCacheLRUEviction(t *testing.T) {
+	c := New[int](Options{Name: "test_lru", TTL: time.Minute, MaxEntries: 2, Clock: clock.NewFakeClock(time.Now())})
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Get("a") // b is now the least recently used
+	c.Set("c", 3)
 
-	time.Sleep(2 * time.Second)
+	if _, ok := c.Get("b"); ok {
+		t.Errorf("expected b to be evicted")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Errorf("expected a to stay")
+	}
+	if _, ok := c.Get("c"); !ok {
+		t.Errorf("expected c to stay")
+	}
+	if evictions := counterValue(t, "evictions", "test_lru", evictionReasonCapacity); evictions != 1 {
+		t.Errorf("capacity evictions = %v, want 1", evictions)
+	}
 
-	item, exists := c.Get(key)
-	log.Printf("2s item: %v, exists: %v", item, exists)
+	// updating an existing key must not evict anything
+	c.Set("a", 10)
+	if c.Len() != 2 {
+		t.Errorf("len = %d, want 2", c.Len())
+	}
+}
 
-	for i := 0; i <= 5; i++ {
-		time.Sleep(7 * time.Second)
-		item, exists := c.Get("key1")
-		log.Printf("time: %v item: %v, exists: %v", time.Now(), item, exists)
+func TestCacheDeleteIfSetBefore(t *testing.T) {
+	c := New[string](Options{Name: "test_version", TTL: time.Minute, Clock: clock.NewFakeClock(time.Now())})
+	c.Set("vm_ip_10.0.0.1", "ins-1")
+	c.Set("vm_ip_10.0.0.2", "ins-2")
+	version := c.Version()
+	// set again after the version was taken
+	c.Set("vm_ip_10.0.0.2", "ins-2")
+	c.Set("vm_ip_10.0.0.3", "ins-3")
+
+	if !c.DeleteIfSetBefore("vm_ip_10.0.0.1", version) {
+		t.Errorf("expected the key set before the version to be deleted")
+	}
+	for _, key := range []string{"vm_ip_10.0.0.2", "vm_ip_10.0.0.3"} {
+		if c.DeleteIfSetBefore(key, version) {
+			t.Errorf("expected %s set after the version to be kept", key)
+		}
+	}
+	if c.DeleteIfSetBefore("vm_ip_10.0.0.4", version) {
+		t.Errorf("expected no delete of a missing key")
+	}
+	if c.Len() != 2 {
+		t.Errorf("len = %d, want 2", c.Len())
+	}
+}
+
+func TestCacheDeleteInvalidateSnapshot(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	c := New[string](Options{Name: "test_snapshot", TTL: time.Minute, Clock: fakeClock})
+	c.Set("vm_ip_10.0.0.1", "ins-1")
+	c.Set("vm_ip_10.0.0.2", "ins-2")
+	c.Set("vm_id_ins-1", "ins-1")
+	c.SetWithTTL("vm_id_ins-2", "ins-2", time.Second)
+
+	if !c.Delete("vm_id_ins-1") || c.Delete("vm_id_ins-1") {
+		t.Errorf("expected Delete to report whether the key was cached")
+	}
+
+	fakeClock.Step(time.Second)
+	expected := map[string]string{"vm_ip_10.0.0.1": "ins-1", "vm_ip_10.0.0.2": "ins-2"}
+	if snapshot := c.Snapshot(); !reflect.DeepEqual(snapshot, expected) {
+		t.Errorf("snapshot = %v, want %v", snapshot, expected)
 	}
 
+	if count := c.Invalidate("vm_ip_"); count != 2 {
+		t.Errorf("invalidated %d keys, want 2", count)
+	}
+	if _, ok := c.Get("vm_ip_10.0.0.1"); ok {
+		t.Errorf("expected vm_ip_ keys to be invalidated")
+	}
 }
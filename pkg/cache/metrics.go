@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+const (
+	metricsNamespace = "tencentcloud"
+	metricsSubsystem = "cache"
+
+	evictionReasonCapacity = "capacity"
+	evictionReasonExpired  = "expired"
+)
+
+var (
+	hitsTotal = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Namespace:      metricsNamespace,
+			Subsystem:      metricsSubsystem,
+			Name:           "hits_total",
+			Help:           "Number of cache lookups that found an unexpired entry.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"cache"},
+	)
+	missesTotal = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Namespace:      metricsNamespace,
+			Subsystem:      metricsSubsystem,
+			Name:           "misses_total",
+			Help:           "Number of cache lookups that found no entry or an expired one.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"cache"},
+	)
+	evictionsTotal = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Namespace:      metricsNamespace,
+			Subsystem:      metricsSubsystem,
+			Name:           "evictions_total",
+			Help:           "Number of cache entries evicted, partitioned by reason (capacity or expired).",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"cache", "reason"},
+	)
+)
+
+func init() {
+	legacyregistry.MustRegister(hitsTotal, missesTotal, evictionsTotal)
+}
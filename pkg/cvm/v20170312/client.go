@@ -0,0 +1,49 @@
+// Package v20170312 is the subset of the tencentcloud cvm 2017-03-12 api used by the cloud provider that
+// github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/cvm v1.0.334 does not ship yet. It mirrors the
+// sdk module so that it can be dropped once the sdk is bumped, by changing the import path only.
+package v20170312
+
+import (
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	tchttp "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/http"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/profile"
+)
+
+const APIVersion = "2017-03-12"
+
+type Client struct {
+	common.Client
+}
+
+func NewClient(credential common.CredentialIface, region string, clientProfile *profile.ClientProfile) (client *Client, err error) {
+	client = &Client{}
+	client.Init(region).
+		WithCredential(credential).
+		WithProfile(clientProfile)
+	return
+}
+
+func NewDescribeTaskInfoRequest() (request *DescribeTaskInfoRequest) {
+	request = &DescribeTaskInfoRequest{
+		BaseRequest: &tchttp.BaseRequest{},
+	}
+	request.Init().WithApiInfo("cvm", APIVersion, "DescribeTaskInfo")
+	return
+}
+
+func NewDescribeTaskInfoResponse() (response *DescribeTaskInfoResponse) {
+	response = &DescribeTaskInfoResponse{
+		BaseResponse: &tchttp.BaseResponse{},
+	}
+	return
+}
+
+// DescribeTaskInfo queries the maintenance tasks of instances
+func (c *Client) DescribeTaskInfo(request *DescribeTaskInfoRequest) (response *DescribeTaskInfoResponse, err error) {
+	if request == nil {
+		request = NewDescribeTaskInfoRequest()
+	}
+	response = NewDescribeTaskInfoResponse()
+	err = c.Send(request, response)
+	return
+}
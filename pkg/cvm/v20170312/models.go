@@ -0,0 +1,101 @@
+package v20170312
+
+import (
+	"encoding/json"
+
+	tchttp "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/http"
+)
+
+type DescribeTaskInfoRequest struct {
+	*tchttp.BaseRequest
+
+	// 返回数量，默认为20，最大值为100。
+	Limit *uint64 `json:"Limit,omitempty" name:"Limit"`
+
+	// 偏移量，默认为0。
+	Offset *uint64 `json:"Offset,omitempty" name:"Offset"`
+
+	// 产品类型，支持取值：CVM、CDH、CPM2.0
+	Product *string `json:"Product,omitempty" name:"Product"`
+
+	// 任务状态：1 待授权，2 处理中，3 已结束，4 已预约，5 已取消，6 已避免
+	TaskStatus []*int64 `json:"TaskStatus,omitempty" name:"TaskStatus"`
+
+	// 任务类型ID：101 实例运行隐患，102 实例运行异常，103 实例硬盘异常，104 实例网络连接异常，105 实例运行预警，106 实例硬盘预警，107 实例维护升级
+	TaskTypeIds []*int64 `json:"TaskTypeIds,omitempty" name:"TaskTypeIds"`
+
+	// 任务ID列表，形如：rep-xxxxxxxx
+	TaskIds []*string `json:"TaskIds,omitempty" name:"TaskIds"`
+
+	// 实例ID列表，形如：ins-xxxxxxxx
+	InstanceIds []*string `json:"InstanceIds,omitempty" name:"InstanceIds"`
+}
+
+func (r *DescribeTaskInfoRequest) ToJsonString() string {
+	b, _ := json.Marshal(r)
+	return string(b)
+}
+
+func (r *DescribeTaskInfoRequest) FromJsonString(s string) error {
+	return json.Unmarshal([]byte(s), &r)
+}
+
+type DescribeTaskInfoResponse struct {
+	*tchttp.BaseResponse
+	Response *struct {
+
+		// 查询返回的维修任务总数量。
+		TotalCount *uint64 `json:"TotalCount,omitempty" name:"TotalCount"`
+
+		// 查询返回的维修任务列表。
+		RepairTaskInfoSet []*RepairTaskInfo `json:"RepairTaskInfoSet,omitempty" name:"RepairTaskInfoSet"`
+
+		// 唯一请求 ID，每次请求都会返回。定位问题时需要提供该次请求的 RequestId。
+		RequestId *string `json:"RequestId,omitempty" name:"RequestId"`
+	} `json:"Response"`
+}
+
+func (r *DescribeTaskInfoResponse) ToJsonString() string {
+	b, _ := json.Marshal(r)
+	return string(b)
+}
+
+func (r *DescribeTaskInfoResponse) FromJsonString(s string) error {
+	return json.Unmarshal([]byte(s), &r)
+}
+
+type RepairTaskInfo struct {
+
+	// 维修任务ID
+	TaskId *string `json:"TaskId,omitempty" name:"TaskId"`
+
+	// 实例ID
+	InstanceId *string `json:"InstanceId,omitempty" name:"InstanceId"`
+
+	// 任务类型ID
+	TaskTypeId *uint64 `json:"TaskTypeId,omitempty" name:"TaskTypeId"`
+
+	// 任务类型中文名称
+	TaskTypeName *string `json:"TaskTypeName,omitempty" name:"TaskTypeName"`
+
+	// 任务状态ID
+	TaskStatus *uint64 `json:"TaskStatus,omitempty" name:"TaskStatus"`
+
+	// 任务创建时间
+	CreateTime *string `json:"CreateTime,omitempty" name:"CreateTime"`
+
+	// 任务授权时间
+	AuthTime *string `json:"AuthTime,omitempty" name:"AuthTime"`
+
+	// 任务结束时间
+	EndTime *string `json:"EndTime,omitempty" name:"EndTime"`
+
+	// 任务详情
+	TaskDetail *string `json:"TaskDetail,omitempty" name:"TaskDetail"`
+
+	// 可用区
+	Zone *string `json:"Zone,omitempty" name:"Zone"`
+
+	// 私有网络ID
+	VpcId *string `json:"VpcId,omitempty" name:"VpcId"`
+}
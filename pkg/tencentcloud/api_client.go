@@ -0,0 +1,400 @@
+package tencentcloud
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	clb "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/clb/v20180317"
+	cloudErrors "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/errors"
+	cvm "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/cvm/v20170312"
+	tke "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/tke/v20180525"
+	cvmext "github.com/weimob-tech/cloud-provider-tencent/pkg/cvm/v20170312"
+	vpc "github.com/weimob-tech/cloud-provider-tencent/pkg/vpc/v20170312"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/klog"
+)
+
+const (
+	defaultAPIRateLimitQPS   = 10
+	defaultAPIRateLimitBurst = 20
+	defaultAPIMaxRetries     = 5
+
+	apiRetryInitialInterval = 500 * time.Millisecond
+	apiRetryMaxInterval     = 10 * time.Second
+)
+
+// retryableErrorCodes are TencentCloudSDKError codes worth retrying a read, sub codes (e.g. InternalError.DbError) included
+var retryableErrorCodes = []string{
+	"RequestLimitExceeded",
+	"InternalError",
+	"ResourceInUse",
+	"FailedOperation.ResourceInOperating",
+}
+
+// mutationRetryableErrorCodes are the codes of the errors a mutation is rejected with before anything is done,
+// safe to retry. An InternalError may come after the mutation is done, retrying it could do it twice.
+var mutationRetryableErrorCodes = []string{
+	"RequestLimitExceeded",
+	"ResourceInUse",
+	"FailedOperation.ResourceInOperating",
+}
+
+// RateLimitConfig is a token bucket for one tencentcloud api action
+type RateLimitConfig struct {
+	QPS   float32 `json:"qps"`
+	Burst int     `json:"burst"`
+}
+
+// clbAPI is the part of the clb client used by the cloud provider
+type clbAPI interface {
+	DescribeLoadBalancers(request *clb.DescribeLoadBalancersRequest) (*clb.DescribeLoadBalancersResponse, error)
+	CreateLoadBalancer(request *clb.CreateLoadBalancerRequest) (*clb.CreateLoadBalancerResponse, error)
+	DeleteLoadBalancer(request *clb.DeleteLoadBalancerRequest) (*clb.DeleteLoadBalancerResponse, error)
+	DescribeListeners(request *clb.DescribeListenersRequest) (*clb.DescribeListenersResponse, error)
+	CreateListener(request *clb.CreateListenerRequest) (*clb.CreateListenerResponse, error)
+	ModifyListener(request *clb.ModifyListenerRequest) (*clb.ModifyListenerResponse, error)
+	DeleteLoadBalancerListeners(request *clb.DeleteLoadBalancerListenersRequest) (*clb.DeleteLoadBalancerListenersResponse, error)
+	DescribeTargets(request *clb.DescribeTargetsRequest) (*clb.DescribeTargetsResponse, error)
+	RegisterTargets(request *clb.RegisterTargetsRequest) (*clb.RegisterTargetsResponse, error)
+	DeregisterTargets(request *clb.DeregisterTargetsRequest) (*clb.DeregisterTargetsResponse, error)
+	DescribeTaskStatus(request *clb.DescribeTaskStatusRequest) (*clb.DescribeTaskStatusResponse, error)
+}
+
+// cvmAPI is the part of the cvm client used by the cloud provider
+type cvmAPI interface {
+	DescribeInstances(request *cvm.DescribeInstancesRequest) (*cvm.DescribeInstancesResponse, error)
+	DescribeTaskInfo(request *cvmext.DescribeTaskInfoRequest) (*cvmext.DescribeTaskInfoResponse, error)
+}
+
+// tkeAPI is the part of the tke client used by the cloud provider
+type tkeAPI interface {
+	DescribeClusterRoutes(request *tke.DescribeClusterRoutesRequest) (*tke.DescribeClusterRoutesResponse, error)
+	CreateClusterRoute(request *tke.CreateClusterRouteRequest) (*tke.CreateClusterRouteResponse, error)
+	DeleteClusterRoute(request *tke.DeleteClusterRouteRequest) (*tke.DeleteClusterRouteResponse, error)
+}
+
+// vpcAPI is the part of the vpc client used by the cloud provider
+type vpcAPI interface {
+	DescribeNetworkInterfaces(request *vpc.DescribeNetworkInterfacesRequest) (*vpc.DescribeNetworkInterfacesResponse, error)
+	DescribeRouteTables(request *vpc.DescribeRouteTablesRequest) (*vpc.DescribeRouteTablesResponse, error)
+	CreateRoutes(request *vpc.CreateRoutesRequest) (*vpc.CreateRoutesResponse, error)
+	DeleteRoutes(request *vpc.DeleteRoutesRequest) (*vpc.DeleteRoutesResponse, error)
+	ReplaceRoutes(request *vpc.ReplaceRoutesRequest) (*vpc.ReplaceRoutesResponse, error)
+	DescribeVpcs(request *vpc.DescribeVpcsRequest) (*vpc.DescribeVpcsResponse, error)
+	DescribeSubnets(request *vpc.DescribeSubnetsRequest) (*vpc.DescribeSubnetsResponse, error)
+}
+
+// apiCaller rate limits tencentcloud api calls per action and retries the retryable ones
+type apiCaller struct {
+	defaultLimit RateLimitConfig
+	limits       map[string]RateLimitConfig
+	maxRetries   int
+	backoff      wait.Backoff
+
+	mu       sync.Mutex
+	limiters map[string]flowcontrol.RateLimiter
+}
+
+// newAPICaller creates an apiCaller from the cloud config
+func newAPICaller(config TxCloudConfig) *apiCaller {
+	return &apiCaller{
+		defaultLimit: config.APIRateLimit,
+		limits:       config.APIRateLimits,
+		maxRetries:   config.APIMaxRetries,
+		backoff: wait.Backoff{
+			Duration: apiRetryInitialInterval,
+			Factor:   2,
+			Jitter:   1,
+			Steps:    config.APIMaxRetries,
+			Cap:      apiRetryMaxInterval,
+		},
+		limiters: make(map[string]flowcontrol.RateLimiter),
+	}
+}
+
+// limiter returns the token bucket of the api action of the service, actions of different services
+// with the same name have their own buckets
+func (c *apiCaller) limiter(service, action string) flowcontrol.RateLimiter {
+	key := service + "/" + action
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if limiter, ok := c.limiters[key]; ok {
+		return limiter
+	}
+	limit, ok := c.limits[key]
+	if !ok {
+		limit, ok = c.limits[action]
+	}
+	if !ok {
+		limit = c.defaultLimit
+	}
+	limiter := flowcontrol.NewTokenBucketRateLimiter(limit.QPS, limit.Burst)
+	c.limiters[key] = limiter
+	return limiter
+}
+
+// call runs fn once the action's rate limit allows it, retrying with jittered backoff on retryable errors
+// until ctx is done
+func (c *apiCaller) call(ctx context.Context, service, action string, fn func() error) error {
+	limiter := c.limiter(service, action)
+	backoff := c.backoff
+	for attempt := 0; ; attempt++ {
+		if err := limiter.Wait(ctx); err != nil {
+			return err
+		}
+		err := fn()
+		if err == nil || attempt >= c.maxRetries || !isRetryableError(action, err) {
+			return err
+		}
+
+		delay := backoff.Step()
+		apiRetriesTotal.WithLabelValues(service, action, err.(*cloudErrors.TencentCloudSDKError).GetCode()).Inc()
+		klog.Warningf("tencentcloud.apiCaller.call: %s.%s retry %d/%d after %v: %v\n", service, action, attempt+1, c.maxRetries, delay, err)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// isMutatingAction check the api action changes resources, every action but the Describe ones
+func isMutatingAction(action string) bool {
+	return !strings.HasPrefix(action, "Describe")
+}
+
+// isRetryableError check the error of the action is a TencentCloudSDKError worth retrying,
+// mutations are only retried on the errors they are rejected with before anything is done
+func isRetryableError(action string, err error) bool {
+	sdkErr, ok := err.(*cloudErrors.TencentCloudSDKError)
+	if !ok {
+		return false
+	}
+	code := sdkErr.GetCode()
+	codes := retryableErrorCodes
+	if isMutatingAction(action) {
+		codes = mutationRetryableErrorCodes
+	}
+	for _, retryable := range codes {
+		if code == retryable || strings.HasPrefix(code, retryable+".") {
+			return true
+		}
+	}
+	// clb returns a plain FailedOperation while another task is running on the same instance
+	return strings.Contains(strings.ToLower(sdkErr.GetMessage()), "task in progress")
+}
+
+// clbClient is a rate limited and retrying clbAPI
+type clbClient struct {
+	client *clb.Client
+	caller *apiCaller
+}
+
+func (c *clbClient) DescribeLoadBalancers(request *clb.DescribeLoadBalancersRequest) (response *clb.DescribeLoadBalancersResponse, err error) {
+	err = c.caller.call(request.GetContext(), "clb", "DescribeLoadBalancers", func() error {
+		response, err = c.client.DescribeLoadBalancers(request)
+		return err
+	})
+	return
+}
+
+func (c *clbClient) CreateLoadBalancer(request *clb.CreateLoadBalancerRequest) (response *clb.CreateLoadBalancerResponse, err error) {
+	err = c.caller.call(request.GetContext(), "clb", "CreateLoadBalancer", func() error {
+		response, err = c.client.CreateLoadBalancer(request)
+		return err
+	})
+	return
+}
+
+func (c *clbClient) DeleteLoadBalancer(request *clb.DeleteLoadBalancerRequest) (response *clb.DeleteLoadBalancerResponse, err error) {
+	err = c.caller.call(request.GetContext(), "clb", "DeleteLoadBalancer", func() error {
+		response, err = c.client.DeleteLoadBalancer(request)
+		return err
+	})
+	return
+}
+
+func (c *clbClient) DescribeListeners(request *clb.DescribeListenersRequest) (response *clb.DescribeListenersResponse, err error) {
+	err = c.caller.call(request.GetContext(), "clb", "DescribeListeners", func() error {
+		response, err = c.client.DescribeListeners(request)
+		return err
+	})
+	return
+}
+
+func (c *clbClient) CreateListener(request *clb.CreateListenerRequest) (response *clb.CreateListenerResponse, err error) {
+	err = c.caller.call(request.GetContext(), "clb", "CreateListener", func() error {
+		response, err = c.client.CreateListener(request)
+		return err
+	})
+	return
+}
+
+func (c *clbClient) ModifyListener(request *clb.ModifyListenerRequest) (response *clb.ModifyListenerResponse, err error) {
+	err = c.caller.call(request.GetContext(), "clb", "ModifyListener", func() error {
+		response, err = c.client.ModifyListener(request)
+		return err
+	})
+	return
+}
+
+func (c *clbClient) DeleteLoadBalancerListeners(request *clb.DeleteLoadBalancerListenersRequest) (response *clb.DeleteLoadBalancerListenersResponse, err error) {
+	err = c.caller.call(request.GetContext(), "clb", "DeleteLoadBalancerListeners", func() error {
+		response, err = c.client.DeleteLoadBalancerListeners(request)
+		return err
+	})
+	return
+}
+
+func (c *clbClient) DescribeTargets(request *clb.DescribeTargetsRequest) (response *clb.DescribeTargetsResponse, err error) {
+	err = c.caller.call(request.GetContext(), "clb", "DescribeTargets", func() error {
+		response, err = c.client.DescribeTargets(request)
+		return err
+	})
+	return
+}
+
+func (c *clbClient) RegisterTargets(request *clb.RegisterTargetsRequest) (response *clb.RegisterTargetsResponse, err error) {
+	err = c.caller.call(request.GetContext(), "clb", "RegisterTargets", func() error {
+		response, err = c.client.RegisterTargets(request)
+		return err
+	})
+	return
+}
+
+func (c *clbClient) DeregisterTargets(request *clb.DeregisterTargetsRequest) (response *clb.DeregisterTargetsResponse, err error) {
+	err = c.caller.call(request.GetContext(), "clb", "DeregisterTargets", func() error {
+		response, err = c.client.DeregisterTargets(request)
+		return err
+	})
+	return
+}
+
+func (c *clbClient) DescribeTaskStatus(request *clb.DescribeTaskStatusRequest) (response *clb.DescribeTaskStatusResponse, err error) {
+	err = c.caller.call(request.GetContext(), "clb", "DescribeTaskStatus", func() error {
+		response, err = c.client.DescribeTaskStatus(request)
+		return err
+	})
+	return
+}
+
+// cvmClient is a rate limited and retrying cvmAPI
+type cvmClient struct {
+	client *cvm.Client
+	// extClient calls the cvm actions the sdk client lacks
+	extClient *cvmext.Client
+	caller    *apiCaller
+}
+
+func (c *cvmClient) DescribeInstances(request *cvm.DescribeInstancesRequest) (response *cvm.DescribeInstancesResponse, err error) {
+	err = c.caller.call(request.GetContext(), "cvm", "DescribeInstances", func() error {
+		response, err = c.client.DescribeInstances(request)
+		return err
+	})
+	return
+}
+
+func (c *cvmClient) DescribeTaskInfo(request *cvmext.DescribeTaskInfoRequest) (response *cvmext.DescribeTaskInfoResponse, err error) {
+	err = c.caller.call(request.GetContext(), "cvm", "DescribeTaskInfo", func() error {
+		response, err = c.extClient.DescribeTaskInfo(request)
+		return err
+	})
+	return
+}
+
+// tkeClient is a rate limited and retrying tkeAPI
+type tkeClient struct {
+	client *tke.Client
+	caller *apiCaller
+}
+
+func (c *tkeClient) DescribeClusterRoutes(request *tke.DescribeClusterRoutesRequest) (response *tke.DescribeClusterRoutesResponse, err error) {
+	err = c.caller.call(request.GetContext(), "tke", "DescribeClusterRoutes", func() error {
+		response, err = c.client.DescribeClusterRoutes(request)
+		return err
+	})
+	return
+}
+
+func (c *tkeClient) CreateClusterRoute(request *tke.CreateClusterRouteRequest) (response *tke.CreateClusterRouteResponse, err error) {
+	err = c.caller.call(request.GetContext(), "tke", "CreateClusterRoute", func() error {
+		response, err = c.client.CreateClusterRoute(request)
+		return err
+	})
+	return
+}
+
+func (c *tkeClient) DeleteClusterRoute(request *tke.DeleteClusterRouteRequest) (response *tke.DeleteClusterRouteResponse, err error) {
+	err = c.caller.call(request.GetContext(), "tke", "DeleteClusterRoute", func() error {
+		response, err = c.client.DeleteClusterRoute(request)
+		return err
+	})
+	return
+}
+
+// vpcClient is a rate limited and retrying vpcAPI
+type vpcClient struct {
+	client *vpc.Client
+	caller *apiCaller
+}
+
+func (c *vpcClient) DescribeNetworkInterfaces(request *vpc.DescribeNetworkInterfacesRequest) (response *vpc.DescribeNetworkInterfacesResponse, err error) {
+	err = c.caller.call(request.GetContext(), "vpc", "DescribeNetworkInterfaces", func() error {
+		response, err = c.client.DescribeNetworkInterfaces(request)
+		return err
+	})
+	return
+}
+
+func (c *vpcClient) DescribeRouteTables(request *vpc.DescribeRouteTablesRequest) (response *vpc.DescribeRouteTablesResponse, err error) {
+	err = c.caller.call(request.GetContext(), "vpc", "DescribeRouteTables", func() error {
+		response, err = c.client.DescribeRouteTables(request)
+		return err
+	})
+	return
+}
+
+func (c *vpcClient) CreateRoutes(request *vpc.CreateRoutesRequest) (response *vpc.CreateRoutesResponse, err error) {
+	err = c.caller.call(request.GetContext(), "vpc", "CreateRoutes", func() error {
+		response, err = c.client.CreateRoutes(request)
+		return err
+	})
+	return
+}
+
+func (c *vpcClient) DeleteRoutes(request *vpc.DeleteRoutesRequest) (response *vpc.DeleteRoutesResponse, err error) {
+	err = c.caller.call(request.GetContext(), "vpc", "DeleteRoutes", func() error {
+		response, err = c.client.DeleteRoutes(request)
+		return err
+	})
+	return
+}
+
+func (c *vpcClient) ReplaceRoutes(request *vpc.ReplaceRoutesRequest) (response *vpc.ReplaceRoutesResponse, err error) {
+	err = c.caller.call(request.GetContext(), "vpc", "ReplaceRoutes", func() error {
+		response, err = c.client.ReplaceRoutes(request)
+		return err
+	})
+	return
+}
+
+func (c *vpcClient) DescribeVpcs(request *vpc.DescribeVpcsRequest) (response *vpc.DescribeVpcsResponse, err error) {
+	err = c.caller.call(request.GetContext(), "vpc", "DescribeVpcs", func() error {
+		response, err = c.client.DescribeVpcs(request)
+		return err
+	})
+	return
+}
+
+func (c *vpcClient) DescribeSubnets(request *vpc.DescribeSubnetsRequest) (response *vpc.DescribeSubnetsResponse, err error) {
+	err = c.caller.call(request.GetContext(), "vpc", "DescribeSubnets", func() error {
+		response, err = c.client.DescribeSubnets(request)
+		return err
+	})
+	return
+}
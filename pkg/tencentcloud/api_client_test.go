@@ -0,0 +1,123 @@
+package tencentcloud
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	cloudErrors "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func newTestAPICaller(maxRetries int) *apiCaller {
+	caller := newAPICaller(TxCloudConfig{
+		APIRateLimit:  RateLimitConfig{QPS: 1000, Burst: 1000},
+		APIMaxRetries: maxRetries,
+	})
+	caller.backoff = wait.Backoff{Duration: time.Millisecond, Steps: maxRetries}
+	return caller
+}
+
+func TestIsRetryableError(t *testing.T) {
+	cases := []struct {
+		action    string
+		err       error
+		retryable bool
+	}{
+		{"DescribeListeners", cloudErrors.NewTencentCloudSDKError("RequestLimitExceeded", "", ""), true},
+		{"DescribeListeners", cloudErrors.NewTencentCloudSDKError("RequestLimitExceeded.UinLimitExceeded", "", ""), true},
+		{"DescribeListeners", cloudErrors.NewTencentCloudSDKError("InternalError", "", ""), true},
+		{"DescribeListeners", cloudErrors.NewTencentCloudSDKError("ResourceInUse", "", ""), true},
+		{"DescribeListeners", cloudErrors.NewTencentCloudSDKError("FailedOperation.ResourceInOperating", "", ""), true},
+		{"DescribeListeners", cloudErrors.NewTencentCloudSDKError("FailedOperation", "Task in progress", ""), true},
+		{"DescribeListeners", cloudErrors.NewTencentCloudSDKError("FailedOperation", "", ""), false},
+		{"DescribeListeners", cloudErrors.NewTencentCloudSDKError("InternalErrorX", "", ""), false},
+		{"DescribeListeners", cloudErrors.NewTencentCloudSDKError("InvalidParameter", "", ""), false},
+		{"DescribeListeners", errors.New("RequestLimitExceeded"), false},
+		// a mutation may be done before an InternalError
+		{"CreateListener", cloudErrors.NewTencentCloudSDKError("RequestLimitExceeded", "", ""), true},
+		{"CreateListener", cloudErrors.NewTencentCloudSDKError("FailedOperation", "Task in progress", ""), true},
+		{"CreateListener", cloudErrors.NewTencentCloudSDKError("InternalError", "", ""), false},
+		{"ReplaceRoutes", cloudErrors.NewTencentCloudSDKError("InternalError.DbError", "", ""), false},
+	}
+	for _, c := range cases {
+		if got := isRetryableError(c.action, c.err); got != c.retryable {
+			t.Errorf("isRetryableError(%s, %v) = %v, want %v", c.action, c.err, got, c.retryable)
+		}
+	}
+}
+
+func TestAPICallerRetriesThrottledCalls(t *testing.T) {
+	caller := newTestAPICaller(3)
+	calls := 0
+	err := caller.call(context.Background(), "clb", "DescribeListeners", func() error {
+		calls++
+		if calls < 3 {
+			return cloudErrors.NewTencentCloudSDKError("RequestLimitExceeded", "", "")
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("expected success after 3 calls, got %d calls, err %v", calls, err)
+	}
+}
+
+func TestAPICallerGivesUp(t *testing.T) {
+	caller := newTestAPICaller(2)
+	calls := 0
+	err := caller.call(context.Background(), "clb", "DescribeListeners", func() error {
+		calls++
+		return cloudErrors.NewTencentCloudSDKError("InternalError", "", "")
+	})
+	if err == nil || calls != 3 {
+		t.Errorf("expected failure after 3 calls, got %d calls, err %v", calls, err)
+	}
+
+	calls = 0
+	err = caller.call(context.Background(), "clb", "DescribeListeners", func() error {
+		calls++
+		return cloudErrors.NewTencentCloudSDKError("InvalidParameter", "", "")
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("expected no retry on non retryable error, got %d calls, err %v", calls, err)
+	}
+}
+
+func TestAPICallerStopsWithContext(t *testing.T) {
+	caller := newTestAPICaller(5)
+	caller.backoff = wait.Backoff{Duration: time.Hour, Steps: 5}
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	done := make(chan error)
+	go func() {
+		done <- caller.call(ctx, "clb", "DescribeListeners", func() error {
+			calls++
+			// the caller gives up while waiting for the retry
+			cancel()
+			return cloudErrors.NewTencentCloudSDKError("RequestLimitExceeded", "", "")
+		})
+	}()
+	select {
+	case err := <-done:
+		if err == nil || calls != 1 {
+			t.Errorf("expected the throttling error after 1 call, got %d calls, err %v", calls, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("call did not return once its context was done")
+	}
+}
+
+func TestAPICallerLimitersPerService(t *testing.T) {
+	caller := newTestAPICaller(0)
+	caller.limits = map[string]RateLimitConfig{"cvm/DescribeTaskInfo": {QPS: 1, Burst: 1}}
+	if caller.limiter("cvm", "DescribeTaskInfo") == caller.limiter("clb", "DescribeTaskInfo") {
+		t.Errorf("actions of different services share a token bucket")
+	}
+	if caller.limiter("cvm", "DescribeTaskInfo") != caller.limiter("cvm", "DescribeTaskInfo") {
+		t.Errorf("an action has several token buckets")
+	}
+	if qps := caller.limiter("cvm", "DescribeTaskInfo").QPS(); qps != 1 {
+		t.Errorf("cvm/DescribeTaskInfo qps = %v, want 1", qps)
+	}
+}
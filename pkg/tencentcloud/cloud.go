@@ -1,8 +1,10 @@
 package tencentcloud
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
@@ -15,15 +17,40 @@ import (
 	cvm "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/cvm/v20170312"
 	tke "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/tke/v20180525"
 	"github.com/weimob-tech/cloud-provider-tencent/pkg/cache"
+	cvmext "github.com/weimob-tech/cloud-provider-tencent/pkg/cvm/v20170312"
+	vpc "github.com/weimob-tech/cloud-provider-tencent/pkg/vpc/v20170312"
+	"golang.org/x/sync/singleflight"
 	cloudProvider "k8s.io/cloud-provider"
 	"k8s.io/klog"
 
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedCoreV1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	toolscache "k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 )
 
 const (
 	providerName = "tencentcloud"
 	TTLTime      = 60 * time.Second
+	// NegativeTTLTime is the lifetime of a cached InstanceNotFound
+	NegativeTTLTime = 10 * time.Second
+	// instanceIDTTLTime is the lifetime of an instance cached by id, an id is never reused
+	instanceIDTTLTime = 5 * time.Minute
+	// instanceAddressTTLTime is the lifetime of an instance cached by private ip or name, both move to new instances
+	instanceAddressTTLTime = 30 * time.Second
+	// loadBalancerTTLTime is the lifetime of a CLB cached by name, our own changes invalidate it
+	loadBalancerTTLTime = 2 * time.Minute
+	// vpcCIDRTTLTime is the lifetime of the cached CIDRs of the vpc and its subnets, checked against the route CIDRs
+	vpcCIDRTTLTime = 5 * time.Minute
+	// routeTableTTLTime is the lifetime of the cached routes of the route table, our own changes invalidate them
+	routeTableTTLTime = 10 * time.Second
+
+	defaultCacheMaxEntries = 10000
 )
 
 type TxCloudConfig struct {
@@ -34,19 +61,98 @@ type TxCloudConfig struct {
 	SecretId          string `json:"secret_id"`
 	SecretKey         string `json:"secret_key"`
 	ClusterRouteTable string `json:"cluster_route_table"`
+	// RouteBackend is where the pod CIDR routes are managed: tke-cluster-route (default), the ClusterRouteTable
+	// TKE cluster route table, or vpc-route-table, the RouteTableId VPC route table
+	RouteBackend string `json:"route_backend"`
+	RouteTableId string `json:"route_table_id"`
+	// AdoptUnownedRoutes makes the vpc-route-table backend adopt the routes without description via a node
+	// of the cluster, i.e. the routes created before routes were owned by a cluster
+	AdoptUnownedRoutes bool `json:"adopt_unowned_routes"`
+	// ClusterName is the --cluster-name of the controller manager, the owner of the routes checked by RouteHealthCheck
+	ClusterName string `json:"cluster_name"`
+	// ClusterCIDR is the --cluster-cidr of the controller manager, comma separated, checked not to overlap the vpc on startup
+	ClusterCIDR string `json:"cluster_cidr"`
+	// NodeNameStrategy is how node names map to CVMs: private-ip (default), instance-id, instance-name or hostname
+	NodeNameStrategy string `json:"node_name_strategy"`
+	// NodeAddressIPFamilyOrder is the ip family of the first InternalIP, i.e. the kubelet's primary ip: ipv4 (default) or ipv6
+	NodeAddressIPFamilyOrder string `json:"node_address_ip_family_order"`
+	// NodeAddressSecondaryENI adds the private ips of the secondary ENIs as InternalIP
+	NodeAddressSecondaryENI bool `json:"node_address_secondary_eni"`
+	// NodeInternalDNSSuffix, when set, adds <hostname>.<suffix> as InternalDNS
+	NodeInternalDNSSuffix string `json:"node_internal_dns_suffix"`
+	// MetadataBaseURL overrides the metadata service of the CVM we are running on, http://169.254.0.23/latest/meta-data/
+	MetadataBaseURL string `json:"metadata_base_url"`
+	// MetadataTimeout is the timeout of a metadata request, in milliseconds
+	MetadataTimeout int `json:"metadata_timeout"`
+
+	// TaskWaitInitialInterval and TaskWaitMaxInterval bound the exponential backoff
+	// between two DescribeTaskStatus polls, in milliseconds
+	TaskWaitInitialInterval int `json:"task_wait_initial_interval"`
+	TaskWaitMaxInterval     int `json:"task_wait_max_interval"`
+	// TaskWaitTimeout is the max wait for a single async task, in seconds
+	TaskWaitTimeout int `json:"task_wait_timeout"`
+
+	// APIRateLimit is the default token bucket of each tencentcloud api action,
+	// APIRateLimits overrides it per action, e.g. "cvm/DescribeInstances", or per action name of every service, e.g. "DescribeInstances"
+	APIRateLimit  RateLimitConfig            `json:"api_rate_limit"`
+	APIRateLimits map[string]RateLimitConfig `json:"api_rate_limits"`
+	// APIMaxRetries is the max retries of a throttled api call, negative disables retry
+	APIMaxRetries int `json:"api_max_retries"`
+
+	// CacheMaxEntries bounds each of the instance, CLB and listener caches. The instance inventory takes about
+	// three entries per instance of the vpc, keep it above that with the inventory enabled.
+	CacheMaxEntries int `json:"cache_max_entries"`
+	// InstanceInventoryInterval is the interval between two listings of every instance of the vpc,
+	// in seconds, negative disables the inventory
+	InstanceInventoryInterval int `json:"instance_inventory_interval"`
+
+	// NodeLabelSync syncs CVM tags and attributes to node labels
+	NodeLabelSync NodeLabelSyncConfig `json:"node_label_sync"`
+	// SpotTermination taints and cordons the nodes of spot instances scheduled for reclamation
+	SpotTermination SpotTerminationConfig `json:"spot_termination"`
+	// MaintenanceEvents turns CVM repair tasks into node conditions and taints
+	MaintenanceEvents MaintenanceEventsConfig `json:"maintenance_events"`
+	// RouteHealthCheck reports the pod CIDR routes missing from the route table and the extra ones
+	RouteHealthCheck RouteHealthCheckConfig `json:"route_health_check"`
 }
 
 type Cloud struct {
 	txConfig   TxCloudConfig
 	kubeClient kubernetes.Interface
-	cvm        *cvm.Client
-	tke        *tke.Client
-	clb        *clb.Client
-	cache      *cache.TTLCache
+	cvm        cvmAPI
+	tke        tkeAPI
+	clb        clbAPI
+	vpc        vpcAPI
+	taskWaiter *TaskWaiter
+	metadata   *MetadataClient
+	// eventRecorder records the Events of nodes and services
+	eventRecorder record.EventRecorder
+	// spotTerminationSource tells when spot instances are reclaimed, picked by SpotTermination.Source
+	spotTerminationSource SpotTerminationNoticeSource
+	// lookupHost overrides net.DefaultResolver.LookupHost for the hostname node name strategy
+	lookupHost func(ctx context.Context, host string) ([]string, error)
+	// clock is the time source of the caches, the real clock by default
+	clock clock.PassiveClock
+	// nodeLister lists the nodes from the node informer started by Initialize
+	nodeLister corelisters.NodeLister
+	// nodeListerSynced tells the node informer has synced once
+	nodeListerSynced toolscache.InformerSynced
+
+	instanceCache     *cache.Cache[*cvm.Instance]
+	instanceLookups   singleflight.Group
+	loadBalancerCache *cache.Cache[*clb.LoadBalancer]
+	listenerCache     *cache.Cache[[]*clb.Listener]
+	routeCIDRCache    *cache.Cache[[]vpcCIDR]
+	tableRouteCache   *cache.Cache[[]*cloudRoute]
 }
 
 //NewCloud Cloud constructed function
 func NewCloud(config io.Reader) (*Cloud, error) {
+	return newCloud(config, ClusterOptions{})
+}
+
+// newCloud constructs the Cloud of the config with the cluster options of the controller manager
+func newCloud(config io.Reader, options ClusterOptions) (*Cloud, error) {
 	var c TxCloudConfig
 	if config != nil {
 		cfg, err := ioutil.ReadAll(config)
@@ -80,13 +186,88 @@ func NewCloud(config io.Reader) (*Cloud, error) {
 	if c.ClusterRouteTable == "" {
 		c.ClusterRouteTable = os.Getenv("TENCENTCLOUD_CLOUD_CONTROLLER_MANAGER_CLUSTER_ROUTE_TABLE")
 	}
+	if c.RouteBackend == "" {
+		c.RouteBackend = os.Getenv("TENCENTCLOUD_CLOUD_CONTROLLER_MANAGER_ROUTE_BACKEND")
+	}
+	if c.RouteTableId == "" {
+		c.RouteTableId = os.Getenv("TENCENTCLOUD_CLOUD_CONTROLLER_MANAGER_ROUTE_TABLE_ID")
+	}
+	if c.ClusterName == "" {
+		c.ClusterName = options.ClusterName
+	}
+	if c.ClusterName == "" {
+		c.ClusterName = defaultClusterName
+	}
+	if c.ClusterCIDR == "" {
+		c.ClusterCIDR = options.ClusterCIDR
+	}
+	if c.NodeNameStrategy == "" {
+		c.NodeNameStrategy = os.Getenv("TENCENTCLOUD_CLOUD_CONTROLLER_MANAGER_NODE_NAME_STRATEGY")
+	}
+	if c.RouteBackend == "" {
+		c.RouteBackend = RouteBackendTKE
+	}
+	if c.NodeNameStrategy == "" {
+		c.NodeNameStrategy = NodeNameStrategyPrivateIP
+	}
+	if c.NodeAddressIPFamilyOrder == "" {
+		c.NodeAddressIPFamilyOrder = NodeAddressIPFamilyIPv4
+	}
+
+	if c.TaskWaitInitialInterval <= 0 {
+		c.TaskWaitInitialInterval = int(defaultTaskWaitInitialInterval / time.Millisecond)
+	}
+	if c.TaskWaitMaxInterval <= 0 {
+		c.TaskWaitMaxInterval = int(defaultTaskWaitMaxInterval / time.Millisecond)
+	}
+	if c.TaskWaitTimeout <= 0 {
+		c.TaskWaitTimeout = int(defaultTaskWaitTimeout / time.Second)
+	}
+
+	if c.APIRateLimit.QPS <= 0 {
+		c.APIRateLimit.QPS = defaultAPIRateLimitQPS
+	}
+	if c.APIRateLimit.Burst <= 0 {
+		c.APIRateLimit.Burst = defaultAPIRateLimitBurst
+	}
+	if c.APIMaxRetries == 0 {
+		c.APIMaxRetries = defaultAPIMaxRetries
+	}
+
+	if c.CacheMaxEntries <= 0 {
+		c.CacheMaxEntries = defaultCacheMaxEntries
+	}
+	if c.InstanceInventoryInterval == 0 {
+		c.InstanceInventoryInterval = int(defaultInstanceInventoryInterval / time.Second)
+	}
+	if c.NodeLabelSync.Interval <= 0 {
+		c.NodeLabelSync.Interval = int(defaultNodeLabelSyncInterval / time.Second)
+	}
+	if c.NodeLabelSync.Prefix == "" {
+		c.NodeLabelSync.Prefix = defaultNodeLabelPrefix
+	}
+	if c.SpotTermination.Interval <= 0 {
+		c.SpotTermination.Interval = int(defaultSpotTerminationInterval / time.Second)
+	}
+	if c.SpotTermination.Source == "" {
+		c.SpotTermination.Source = SpotTerminationSourceMetadata
+	}
+	if c.MaintenanceEvents.Interval <= 0 {
+		c.MaintenanceEvents.Interval = int(defaultMaintenanceEventsInterval / time.Second)
+	}
+	if c.RouteHealthCheck.Interval <= 0 {
+		c.RouteHealthCheck.Interval = int(defaultRouteHealthCheckInterval / time.Second)
+	}
 
 	if err := checkConfig(c); err != nil {
 		klog.V(3).Infof("tencentcloud.NewCloud: return: nil, %v\n", err)
 		return nil, err
 	}
 
-	return &Cloud{txConfig: c}, nil
+	return &Cloud{
+		txConfig: c,
+		metadata: NewMetadataClient(c.MetadataBaseURL, time.Duration(c.MetadataTimeout)*time.Millisecond),
+	}, nil
 }
 
 // checkConfig check cloud config
@@ -115,18 +296,62 @@ func checkConfig(c TxCloudConfig) error {
 		klog.Error("tencentcloud.checkConfig: 'SecretKey' config is null\n")
 		return errors.New("'SecretKey' config is null")
 	}
-	if strings.TrimSpace(c.ClusterRouteTable) == "" {
+	if c.RouteBackend != "" && !isValidRouteBackend(c.RouteBackend) {
+		klog.Errorf("tencentcloud.checkConfig: 'RouteBackend' config %s is not supported\n", c.RouteBackend)
+		return fmt.Errorf("'RouteBackend' config %s is not supported", c.RouteBackend)
+	}
+	if c.RouteBackend != RouteBackendVPC && strings.TrimSpace(c.ClusterRouteTable) == "" {
 		klog.Error("tencentcloud.checkConfig: 'ClusterRouteTable' config is null\n")
 		return errors.New("'ClusterRouteTable' config is null")
 	}
+	if c.RouteBackend == RouteBackendVPC && strings.TrimSpace(c.RouteTableId) == "" {
+		klog.Error("tencentcloud.checkConfig: 'RouteTableId' config is null\n")
+		return errors.New("'RouteTableId' config is null")
+	}
+	if !isValidNodeNameStrategy(c.NodeNameStrategy) {
+		klog.Errorf("tencentcloud.checkConfig: 'NodeNameStrategy' config %s is not supported\n", c.NodeNameStrategy)
+		return fmt.Errorf("'NodeNameStrategy' config %s is not supported", c.NodeNameStrategy)
+	}
+	if c.NodeAddressIPFamilyOrder != "" && c.NodeAddressIPFamilyOrder != NodeAddressIPFamilyIPv4 &&
+		c.NodeAddressIPFamilyOrder != NodeAddressIPFamilyIPv6 {
+		klog.Errorf("tencentcloud.checkConfig: 'NodeAddressIPFamilyOrder' config %s is not supported\n", c.NodeAddressIPFamilyOrder)
+		return fmt.Errorf("'NodeAddressIPFamilyOrder' config %s is not supported", c.NodeAddressIPFamilyOrder)
+	}
+	if c.NodeLabelSync.Enabled {
+		if err := checkNodeLabelPrefix(c.NodeLabelSync.Prefix); err != nil {
+			klog.Errorf("tencentcloud.checkConfig: 'NodeLabelSync.Prefix' config %s is invalid: %v\n", c.NodeLabelSync.Prefix, err)
+			return fmt.Errorf("'NodeLabelSync.Prefix' config %s is invalid: %v", c.NodeLabelSync.Prefix, err)
+		}
+	}
+	switch v1.TaintEffect(c.SpotTermination.TaintEffect) {
+	case "", v1.TaintEffectNoSchedule, v1.TaintEffectNoExecute:
+	default:
+		klog.Errorf("tencentcloud.checkConfig: 'SpotTermination.TaintEffect' config %s is not supported\n", c.SpotTermination.TaintEffect)
+		return fmt.Errorf("'SpotTermination.TaintEffect' config %s is not supported", c.SpotTermination.TaintEffect)
+	}
+	switch c.SpotTermination.Source {
+	case "", SpotTerminationSourceMetadata, SpotTerminationSourceNodeAnnotation:
+	default:
+		klog.Errorf("tencentcloud.checkConfig: 'SpotTermination.Source' config %s is not supported\n", c.SpotTermination.Source)
+		return fmt.Errorf("'SpotTermination.Source' config %s is not supported", c.SpotTermination.Source)
+	}
 	return nil
 }
 
-// init Initialize cloudProvider
-func init() {
+// ClusterOptions are the flags of the controller manager the cloud provider needs
+type ClusterOptions struct {
+	// ClusterName is --cluster-name, the owner of the routes
+	ClusterName string
+	// ClusterCIDR is --cluster-cidr, the comma separated CIDRs of the pods
+	ClusterCIDR string
+}
+
+// RegisterCloudProvider registers the tencentcloud cloud provider, options fill the cluster_name
+// and the cluster_cidr the cloud config leaves empty
+func RegisterCloudProvider(options ClusterOptions) {
 	cloudProvider.RegisterCloudProvider(providerName,
 		func(config io.Reader) (cloudProvider.Interface, error) {
-			return NewCloud(config)
+			return newCloud(config, options)
 		})
 }
 
@@ -134,6 +359,14 @@ func init() {
 // to perform housekeeping activities within the cloud provider.
 func (cloud *Cloud) Initialize(clientBuilder cloudProvider.ControllerClientBuilder, stop <-chan struct{}) {
 	cloud.kubeClient = clientBuilder.ClientOrDie("tencentcloud-cloud-provider")
+	informerFactory := informers.NewSharedInformerFactory(cloud.kubeClient, 0)
+	nodeInformer := informerFactory.Core().V1().Nodes()
+	cloud.nodeLister = nodeInformer.Lister()
+	cloud.nodeListerSynced = nodeInformer.Informer().HasSynced
+	informerFactory.Start(stop)
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartRecordingToSink(&typedCoreV1.EventSinkImpl{Interface: cloud.kubeClient.CoreV1().Events("")})
+	cloud.eventRecorder = broadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: "tencentcloud-cloud-provider"})
 	credential := common.NewCredential(
 		//os.Getenv("TENCENTCLOUD_SECRET_ID"),
 		//os.Getenv("TENCENTCLOUD_SECRET_KEY"),
@@ -146,25 +379,114 @@ func (cloud *Cloud) Initialize(clientBuilder cloudProvider.ControllerClientBuild
 	// SDK有默认的超时时间，非必要请不要进行调整。
 	// 如有需要请在代码中查阅以获取最新的默认值。
 	cpf.HttpProfile.ReqTimeout = 10
-	cvmClient, err := cvm.NewClient(credential, cloud.txConfig.Region, cpf)
+	caller := newAPICaller(cloud.txConfig)
+	cvmSdkClient, err := cvm.NewClient(credential, cloud.txConfig.Region, cpf)
 	if err != nil {
 		klog.Warningf("tencentcloud.Initialize().cvm.NewClient An tencentcloud API error has returned, message=[%v])\n", err)
 	}
-	cloud.cvm = cvmClient
+	cvmExtClient, err := cvmext.NewClient(credential, cloud.txConfig.Region, cpf)
+	if err != nil {
+		klog.Warningf("tencentcloud.Initialize().cvmext.NewClient An tencentcloud API error has returned, message=[%v])\n", err)
+	}
+	cloud.cvm = &cvmClient{client: cvmSdkClient, extClient: cvmExtClient, caller: caller}
 
-	tkeClient, err := tke.NewClient(credential, cloud.txConfig.Region, cpf)
+	tkeSdkClient, err := tke.NewClient(credential, cloud.txConfig.Region, cpf)
 	if err != nil {
 		klog.Warningf("tencentcloud.Initialize().tke.NewClient An tencentcloud API error has returned, message=[%v])\n", err)
 	}
-	cloud.tke = tkeClient
+	cloud.tke = &tkeClient{client: tkeSdkClient, caller: caller}
 
-	clbClient, err := clb.NewClient(credential, cloud.txConfig.Region, cpf)
+	clbSdkClient, err := clb.NewClient(credential, cloud.txConfig.Region, cpf)
 	if err != nil {
 		klog.Warningf("tencentcloud.Initialize().clb.NewClient An tencentcloud API error has returned, message=[%v])\n", err)
 	}
-	cloud.clb = clbClient
+	cloud.clb = &clbClient{client: clbSdkClient, caller: caller}
 
-	cloud.cache = cache.NewTTLCache(TTLTime)
+	vpcSdkClient, err := vpc.NewClient(credential, cloud.txConfig.Region, cpf)
+	if err != nil {
+		klog.Warningf("tencentcloud.Initialize().vpc.NewClient An tencentcloud API error has returned, message=[%v])\n", err)
+	}
+	cloud.vpc = &vpcClient{client: vpcSdkClient, caller: caller}
+	cloud.taskWaiter = NewTaskWaiter(cloud.clb,
+		time.Duration(cloud.txConfig.TaskWaitInitialInterval)*time.Millisecond,
+		time.Duration(cloud.txConfig.TaskWaitMaxInterval)*time.Millisecond,
+		time.Duration(cloud.txConfig.TaskWaitTimeout)*time.Second)
+
+	cloud.initCaches()
+
+	if err := cloud.checkIdentity(context.TODO()); errors.Is(err, ErrNotOnCVM) {
+		klog.V(3).Infof("tencentcloud.Initialize: %v\n", err)
+	} else if err != nil {
+		klog.Warningf("tencentcloud.Initialize: identity check error: %v\n", err)
+	}
+	var conflict *RouteCIDRConflictError
+	if err := cloud.checkClusterCIDR(context.TODO()); errors.As(err, &conflict) {
+		klog.Fatalf("tencentcloud.Initialize: --cluster-cidr check error: %v\n", err)
+	} else if err != nil {
+		klog.Warningf("tencentcloud.Initialize: --cluster-cidr check error: %v\n", err)
+	}
+
+	if cloud.txConfig.InstanceInventoryInterval > 0 {
+		go cloud.runInstanceInventory(time.Duration(cloud.txConfig.InstanceInventoryInterval)*time.Second, stop)
+	}
+	if cloud.txConfig.NodeLabelSync.Enabled {
+		go cloud.runNodeLabelSync(time.Duration(cloud.txConfig.NodeLabelSync.Interval)*time.Second, stop)
+	}
+	if cloud.txConfig.SpotTermination.Enabled {
+		if cloud.spotTerminationSource == nil {
+			cloud.spotTerminationSource = cloud.newSpotTerminationSource()
+		}
+		go cloud.runSpotTermination(time.Duration(cloud.txConfig.SpotTermination.Interval)*time.Second, stop)
+	}
+	if cloud.txConfig.MaintenanceEvents.Enabled {
+		go cloud.runMaintenanceEvents(time.Duration(cloud.txConfig.MaintenanceEvents.Interval)*time.Second, stop)
+	}
+	if cloud.txConfig.RouteHealthCheck.Enabled {
+		go cloud.runRouteHealthCheck(time.Duration(cloud.txConfig.RouteHealthCheck.Interval)*time.Second, stop)
+	}
+}
+
+// initCaches creates the caches of tencentcloud resources
+func (cloud *Cloud) initCaches() {
+	cloud.instanceCache = cache.New[*cvm.Instance](cache.Options{
+		Name: "instance",
+		TTL:  TTLTime,
+		NamespaceTTLs: map[string]time.Duration{
+			cacheNamePreVmID:   instanceIDTTLTime,
+			cacheNamePreVmIp:   instanceAddressTTLTime,
+			cacheNamePreVmName: instanceAddressTTLTime,
+		},
+		MaxEntries: cloud.txConfig.CacheMaxEntries,
+		Clock:      cloud.clock,
+	})
+	cloud.loadBalancerCache = cache.New[*clb.LoadBalancer](cache.Options{
+		Name:          "clb",
+		TTL:           TTLTime,
+		NamespaceTTLs: map[string]time.Duration{cacheNamePreCLB: loadBalancerTTLTime},
+		MaxEntries:    cloud.txConfig.CacheMaxEntries,
+		Clock:         cloud.clock,
+	})
+	cloud.listenerCache = cache.New[[]*clb.Listener](cache.Options{
+		Name:          "clb_listener",
+		TTL:           TTLTime,
+		NamespaceTTLs: map[string]time.Duration{cacheNamePreCLBListener: loadBalancerTTLTime},
+		MaxEntries:    cloud.txConfig.CacheMaxEntries,
+		Clock:         cloud.clock,
+	})
+	cloud.routeCIDRCache = cache.New[[]vpcCIDR](cache.Options{
+		Name:          "vpc_cidr",
+		TTL:           TTLTime,
+		NamespaceTTLs: map[string]time.Duration{cacheNamePreVpcCIDR: vpcCIDRTTLTime},
+		MaxEntries:    cloud.txConfig.CacheMaxEntries,
+		Clock:         cloud.clock,
+	})
+	cloud.tableRouteCache = cache.New[[]*cloudRoute](cache.Options{
+		Name:          "route_table",
+		TTL:           TTLTime,
+		NamespaceTTLs: map[string]time.Duration{cacheNamePreRouteTable: routeTableTTLTime},
+		MaxEntries:    cloud.txConfig.CacheMaxEntries,
+		Clock:         cloud.clock,
+	})
 }
 
 // LoadBalancer returns a balancer interface. Also returns true if the interface is supported, false otherwise.
@@ -179,7 +501,7 @@ func (cloud *Cloud) Instances() (cloudProvider.Instances, bool) {
 
 // Zones returns a zones interface. Also returns true if the interface is supported, false otherwise.
 func (cloud *Cloud) Zones() (cloudProvider.Zones, bool) {
-	return nil, false
+	return cloud, true
 }
 
 // Clusters returns a clusters interface.  Also returns true if the interface is supported, false otherwise.
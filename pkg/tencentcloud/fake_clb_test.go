@@ -0,0 +1,313 @@
+package tencentcloud
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	clb "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/clb/v20180317"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+)
+
+// fillResponse fills a tencentcloud sdk response from the value of its Response field
+func fillResponse(response interface{ FromJsonString(string) error }, body interface{}) {
+	b, err := json.Marshal(map[string]interface{}{"Response": body})
+	if err != nil {
+		panic(err)
+	}
+	if err := response.FromJsonString(string(b)); err != nil {
+		panic(err)
+	}
+}
+
+// fakeCLB is an in memory clbAPI, every async task succeeds immediately
+type fakeCLB struct {
+	mu            sync.Mutex
+	nextId        int
+	loadBalancers []*clb.LoadBalancer
+	clientTokens  map[string]string
+	listeners     map[string][]*clb.Listener
+	targets       map[string][]*clb.Backend
+	calls         map[string]int
+
+	// createLoadBalancerErr is returned by CreateLoadBalancer after the CLB has been created
+	createLoadBalancerErr error
+}
+
+func newFakeCLB() *fakeCLB {
+	return &fakeCLB{
+		clientTokens: make(map[string]string),
+		listeners:    make(map[string][]*clb.Listener),
+		targets:      make(map[string][]*clb.Backend),
+		calls:        make(map[string]int),
+	}
+}
+
+func (f *fakeCLB) newId(prefix string) string {
+	f.nextId++
+	return fmt.Sprintf("%s-%08d", prefix, f.nextId)
+}
+
+func (f *fakeCLB) requestId(action string) map[string]interface{} {
+	f.calls[action]++
+	return map[string]interface{}{"RequestId": f.newId("req")}
+}
+
+func (f *fakeCLB) callCount(action string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[action]
+}
+
+func (f *fakeCLB) DescribeLoadBalancers(request *clb.DescribeLoadBalancersRequest) (*clb.DescribeLoadBalancersResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	body := f.requestId("DescribeLoadBalancers")
+	set := make([]*clb.LoadBalancer, 0)
+	for _, loadBalancer := range f.loadBalancers {
+		if request.LoadBalancerName != nil && *request.LoadBalancerName != *loadBalancer.LoadBalancerName {
+			continue
+		}
+		if len(request.LoadBalancerIds) > 0 && !containsString(common.StringValues(request.LoadBalancerIds), *loadBalancer.LoadBalancerId) {
+			continue
+		}
+		if !matchTagFilters(loadBalancer.Tags, request.Filters) {
+			continue
+		}
+		set = append(set, loadBalancer)
+	}
+	body["LoadBalancerSet"] = set
+	body["TotalCount"] = len(set)
+	response := clb.NewDescribeLoadBalancersResponse()
+	fillResponse(response, body)
+	return response, nil
+}
+
+func matchTagFilters(tags []*clb.TagInfo, filters []*clb.Filter) bool {
+	for _, filter := range filters {
+		if !strings.HasPrefix(*filter.Name, "tag:") {
+			continue
+		}
+		key := strings.TrimPrefix(*filter.Name, "tag:")
+		matched := false
+		for _, tag := range tags {
+			if *tag.TagKey == key && *tag.TagValue == *filter.Values[0] {
+				matched = true
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *fakeCLB) CreateLoadBalancer(request *clb.CreateLoadBalancerRequest) (*clb.CreateLoadBalancerResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	body := f.requestId("CreateLoadBalancer")
+	id, ok := "", false
+	if request.ClientToken != nil {
+		id, ok = f.clientTokens[*request.ClientToken]
+	}
+	if !ok {
+		id = f.newId("lb")
+		loadBalancer := &clb.LoadBalancer{
+			LoadBalancerId:   common.StringPtr(id),
+			LoadBalancerName: request.LoadBalancerName,
+			LoadBalancerType: request.LoadBalancerType,
+			LoadBalancerVips: common.StringPtrs([]string{"10.0.0.1"}),
+			VpcId:            request.VpcId,
+			SubnetId:         request.SubnetId,
+			Tags:             request.Tags,
+		}
+		if loadBalancer.SubnetId == nil {
+			loadBalancer.SubnetId = common.StringPtr("")
+		}
+		f.loadBalancers = append(f.loadBalancers, loadBalancer)
+		if request.ClientToken != nil {
+			f.clientTokens[*request.ClientToken] = id
+		}
+	}
+	if f.createLoadBalancerErr != nil {
+		err := f.createLoadBalancerErr
+		f.createLoadBalancerErr = nil
+		return nil, err
+	}
+	body["LoadBalancerIds"] = []string{id}
+	response := clb.NewCreateLoadBalancerResponse()
+	fillResponse(response, body)
+	return response, nil
+}
+
+func (f *fakeCLB) DeleteLoadBalancer(request *clb.DeleteLoadBalancerRequest) (*clb.DeleteLoadBalancerResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	body := f.requestId("DeleteLoadBalancer")
+	for _, id := range request.LoadBalancerIds {
+		for idx, loadBalancer := range f.loadBalancers {
+			if *loadBalancer.LoadBalancerId == *id {
+				f.loadBalancers = append(f.loadBalancers[:idx], f.loadBalancers[idx+1:]...)
+				break
+			}
+		}
+		delete(f.listeners, *id)
+	}
+	response := clb.NewDeleteLoadBalancerResponse()
+	fillResponse(response, body)
+	return response, nil
+}
+
+func (f *fakeCLB) DescribeListeners(request *clb.DescribeListenersRequest) (*clb.DescribeListenersResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	body := f.requestId("DescribeListeners")
+	body["Listeners"] = f.listeners[*request.LoadBalancerId]
+	body["TotalCount"] = len(f.listeners[*request.LoadBalancerId])
+	response := clb.NewDescribeListenersResponse()
+	fillResponse(response, body)
+	return response, nil
+}
+
+func (f *fakeCLB) CreateListener(request *clb.CreateListenerRequest) (*clb.CreateListenerResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	body := f.requestId("CreateListener")
+	ids := make([]string, 0)
+	for idx, port := range request.Ports {
+		for _, listener := range f.listeners[*request.LoadBalancerId] {
+			if *listener.Port == *port && *listener.Protocol == *request.Protocol {
+				return nil, fmt.Errorf("listener %s/%d already exists", *request.Protocol, *port)
+			}
+		}
+		id := f.newId("lbl")
+		f.listeners[*request.LoadBalancerId] = append(f.listeners[*request.LoadBalancerId], &clb.Listener{
+			ListenerId:   common.StringPtr(id),
+			Protocol:     request.Protocol,
+			Port:         port,
+			ListenerName: request.ListenerNames[idx],
+		})
+		ids = append(ids, id)
+	}
+	body["ListenerIds"] = ids
+	response := clb.NewCreateListenerResponse()
+	fillResponse(response, body)
+	return response, nil
+}
+
+func (f *fakeCLB) ModifyListener(request *clb.ModifyListenerRequest) (*clb.ModifyListenerResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	body := f.requestId("ModifyListener")
+	for _, listener := range f.listeners[*request.LoadBalancerId] {
+		if *listener.ListenerId == *request.ListenerId && request.ListenerName != nil {
+			listener.ListenerName = request.ListenerName
+		}
+	}
+	response := clb.NewModifyListenerResponse()
+	fillResponse(response, body)
+	return response, nil
+}
+
+func (f *fakeCLB) DeleteLoadBalancerListeners(request *clb.DeleteLoadBalancerListenersRequest) (*clb.DeleteLoadBalancerListenersResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	body := f.requestId("DeleteLoadBalancerListeners")
+	remain := make([]*clb.Listener, 0)
+	for _, listener := range f.listeners[*request.LoadBalancerId] {
+		deleted := false
+		for _, id := range request.ListenerIds {
+			if *id == *listener.ListenerId {
+				deleted = true
+			}
+		}
+		if !deleted {
+			remain = append(remain, listener)
+		}
+	}
+	f.listeners[*request.LoadBalancerId] = remain
+	response := clb.NewDeleteLoadBalancerListenersResponse()
+	fillResponse(response, body)
+	return response, nil
+}
+
+func (f *fakeCLB) DescribeTargets(request *clb.DescribeTargetsRequest) (*clb.DescribeTargetsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	body := f.requestId("DescribeTargets")
+	backends := make([]*clb.ListenerBackend, 0)
+	for _, listener := range f.listeners[*request.LoadBalancerId] {
+		backends = append(backends, &clb.ListenerBackend{
+			ListenerId: listener.ListenerId,
+			Protocol:   listener.Protocol,
+			Port:       listener.Port,
+			Targets:    f.targets[*listener.ListenerId],
+		})
+	}
+	body["Listeners"] = backends
+	response := clb.NewDescribeTargetsResponse()
+	fillResponse(response, body)
+	return response, nil
+}
+
+func (f *fakeCLB) RegisterTargets(request *clb.RegisterTargetsRequest) (*clb.RegisterTargetsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	body := f.requestId("RegisterTargets")
+	for _, target := range request.Targets {
+		f.targets[*request.ListenerId] = append(f.targets[*request.ListenerId], &clb.Backend{InstanceId: target.InstanceId, Port: target.Port})
+	}
+	response := clb.NewRegisterTargetsResponse()
+	fillResponse(response, body)
+	return response, nil
+}
+
+func (f *fakeCLB) DeregisterTargets(request *clb.DeregisterTargetsRequest) (*clb.DeregisterTargetsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	body := f.requestId("DeregisterTargets")
+	remain := make([]*clb.Backend, 0)
+	for _, backend := range f.targets[*request.ListenerId] {
+		deleted := false
+		for _, target := range request.Targets {
+			if *target.InstanceId == *backend.InstanceId && *target.Port == *backend.Port {
+				deleted = true
+			}
+		}
+		if !deleted {
+			remain = append(remain, backend)
+		}
+	}
+	f.targets[*request.ListenerId] = remain
+	response := clb.NewDeregisterTargetsResponse()
+	fillResponse(response, body)
+	return response, nil
+}
+
+func (f *fakeCLB) DescribeTaskStatus(request *clb.DescribeTaskStatusRequest) (*clb.DescribeTaskStatusResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	body := f.requestId("DescribeTaskStatus")
+	body["Status"] = taskStatusSucceeded
+	response := clb.NewDescribeTaskStatusResponse()
+	fillResponse(response, body)
+	return response, nil
+}
+
+// newTestCloud creates a Cloud backed by fake tencentcloud apis
+func newTestCloud(fakeClb *fakeCLB, fakeCvm *fakeCVM) *Cloud {
+	cloud := &Cloud{
+		txConfig: TxCloudConfig{
+			Region:        "ap-guangzhou",
+			VpcId:         "vpc-test",
+			CLBNamePrefix: "test",
+			TagKey:        "cluster",
+		},
+		clb: fakeClb,
+		cvm: fakeCvm,
+	}
+	cloud.initCaches()
+	cloud.taskWaiter = NewTaskWaiter(fakeClb, defaultTaskWaitInitialInterval, defaultTaskWaitMaxInterval, defaultTaskWaitTimeout)
+	return cloud
+}
@@ -0,0 +1,172 @@
+package tencentcloud
+
+import (
+	"sync"
+
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	cvm "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/cvm/v20170312"
+	cvmext "github.com/weimob-tech/cloud-provider-tencent/pkg/cvm/v20170312"
+)
+
+// fakeCVM is an in memory cvmAPI supporting the filters used by the cloud provider
+type fakeCVM struct {
+	mu        sync.Mutex
+	instances []*cvm.Instance
+	tasks     []*cvmext.RepairTaskInfo
+	calls     int
+
+	// gate, when set, blocks every DescribeInstances call until it is closed
+	gate chan struct{}
+}
+
+func newFakeCVM(instances ...*cvm.Instance) *fakeCVM {
+	return &fakeCVM{instances: instances}
+}
+
+// newTestInstance creates a running instance in the test vpc
+func newTestInstance(instanceId, privateIp string) *cvm.Instance {
+	return &cvm.Instance{
+		InstanceId:          common.StringPtr(instanceId),
+		InstanceName:        common.StringPtr(instanceId),
+		InstanceType:        common.StringPtr("S5.MEDIUM4"),
+		InstanceState:       common.StringPtr("RUNNING"),
+		InstanceChargeType:  common.StringPtr("POSTPAID_BY_HOUR"),
+		PrivateIpAddresses:  common.StringPtrs([]string{privateIp}),
+		Placement:           &cvm.Placement{Zone: common.StringPtr("ap-guangzhou-3"), ProjectId: common.Int64Ptr(0)},
+		VirtualPrivateCloud: &cvm.VirtualPrivateCloud{VpcId: common.StringPtr("vpc-test"), SubnetId: common.StringPtr("subnet-test")},
+	}
+}
+
+func (f *fakeCVM) callCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+func (f *fakeCVM) DescribeInstances(request *cvm.DescribeInstancesRequest) (*cvm.DescribeInstancesResponse, error) {
+	f.mu.Lock()
+	f.calls++
+	gate := f.gate
+	f.mu.Unlock()
+	if gate != nil {
+		<-gate
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	set := make([]*cvm.Instance, 0)
+	for _, instance := range f.instances {
+		if matchInstanceFilters(instance, request) {
+			set = append(set, instance)
+		}
+	}
+	total := len(set)
+	if request.Offset != nil && int(*request.Offset) < len(set) {
+		set = set[*request.Offset:]
+	} else if request.Offset != nil {
+		set = nil
+	}
+	if request.Limit != nil && int(*request.Limit) < len(set) {
+		set = set[:*request.Limit]
+	}
+
+	response := cvm.NewDescribeInstancesResponse()
+	fillResponse(response, map[string]interface{}{
+		"InstanceSet": set,
+		"TotalCount":  total,
+		"RequestId":   "req-cvm",
+	})
+	return response, nil
+}
+
+// addRepairTask adds a repair task of instanceId
+func (f *fakeCVM) addRepairTask(taskId, instanceId string, taskType, status uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tasks = append(f.tasks, &cvmext.RepairTaskInfo{
+		TaskId:     common.StringPtr(taskId),
+		InstanceId: common.StringPtr(instanceId),
+		TaskTypeId: common.Uint64Ptr(taskType),
+		TaskStatus: common.Uint64Ptr(status),
+	})
+}
+
+// setRepairTaskStatus changes the status of the repair task taskId
+func (f *fakeCVM) setRepairTaskStatus(taskId string, status uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, task := range f.tasks {
+		if *task.TaskId == taskId {
+			task.TaskStatus = common.Uint64Ptr(status)
+		}
+	}
+}
+
+func (f *fakeCVM) DescribeTaskInfo(request *cvmext.DescribeTaskInfoRequest) (*cvmext.DescribeTaskInfoResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	set := make([]*cvmext.RepairTaskInfo, 0)
+	for _, task := range f.tasks {
+		matched := len(request.TaskStatus) == 0
+		for _, status := range request.TaskStatus {
+			matched = matched || uint64(*status) == *task.TaskStatus
+		}
+		if matched {
+			set = append(set, task)
+		}
+	}
+	total := len(set)
+	if request.Offset != nil && int(*request.Offset) < len(set) {
+		set = set[*request.Offset:]
+	} else if request.Offset != nil {
+		set = nil
+	}
+	if request.Limit != nil && int(*request.Limit) < len(set) {
+		set = set[:*request.Limit]
+	}
+
+	response := cvmext.NewDescribeTaskInfoResponse()
+	fillResponse(response, map[string]interface{}{
+		"RepairTaskInfoSet": set,
+		"TotalCount":        total,
+		"RequestId":         "req-cvm",
+	})
+	return response, nil
+}
+
+func matchInstanceFilters(instance *cvm.Instance, request *cvm.DescribeInstancesRequest) bool {
+	if len(request.InstanceIds) > 0 && !containsString(common.StringValues(request.InstanceIds), *instance.InstanceId) {
+		return false
+	}
+	for _, filter := range request.Filters {
+		values := common.StringValues(filter.Values)
+		var matched bool
+		switch *filter.Name {
+		case "private-ip-address":
+			for _, ip := range instance.PrivateIpAddresses {
+				matched = matched || containsString(values, *ip)
+			}
+		case "instance-id":
+			matched = containsString(values, *instance.InstanceId)
+		case "instance-name":
+			matched = containsString(values, *instance.InstanceName)
+		case "vpc-id":
+			matched = containsString(values, *instance.VirtualPrivateCloud.VpcId)
+		default:
+			matched = true
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
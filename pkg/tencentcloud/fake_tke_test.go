@@ -0,0 +1,60 @@
+package tencentcloud
+
+import (
+	"sync"
+
+	tke "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/tke/v20180525"
+)
+
+// fakeTKE is an in memory tkeAPI
+type fakeTKE struct {
+	mu     sync.Mutex
+	routes []*tke.RouteInfo
+}
+
+func (f *fakeTKE) DescribeClusterRoutes(request *tke.DescribeClusterRoutesRequest) (*tke.DescribeClusterRoutesResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	set := make([]*tke.RouteInfo, 0)
+	for _, route := range f.routes {
+		if *route.RouteTableName == *request.RouteTableName {
+			set = append(set, route)
+		}
+	}
+	response := tke.NewDescribeClusterRoutesResponse()
+	fillResponse(response, map[string]interface{}{
+		"RouteSet":   set,
+		"TotalCount": len(set),
+		"RequestId":  "req-tke",
+	})
+	return response, nil
+}
+
+func (f *fakeTKE) CreateClusterRoute(request *tke.CreateClusterRouteRequest) (*tke.CreateClusterRouteResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.routes = append(f.routes, &tke.RouteInfo{
+		RouteTableName:       request.RouteTableName,
+		DestinationCidrBlock: request.DestinationCidrBlock,
+		GatewayIp:            request.GatewayIp,
+	})
+	response := tke.NewCreateClusterRouteResponse()
+	fillResponse(response, map[string]interface{}{"RequestId": "req-tke"})
+	return response, nil
+}
+
+func (f *fakeTKE) DeleteClusterRoute(request *tke.DeleteClusterRouteRequest) (*tke.DeleteClusterRouteResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	kept := make([]*tke.RouteInfo, 0, len(f.routes))
+	for _, route := range f.routes {
+		if *route.RouteTableName != *request.RouteTableName || *route.DestinationCidrBlock != *request.DestinationCidrBlock ||
+			*route.GatewayIp != *request.GatewayIp {
+			kept = append(kept, route)
+		}
+	}
+	f.routes = kept
+	response := tke.NewDeleteClusterRouteResponse()
+	fillResponse(response, map[string]interface{}{"RequestId": "req-tke"})
+	return response, nil
+}
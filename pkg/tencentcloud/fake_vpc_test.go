@@ -0,0 +1,306 @@
+package tencentcloud
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/errors"
+	vpc "github.com/weimob-tech/cloud-provider-tencent/pkg/vpc/v20170312"
+)
+
+// fakeVPC is an in memory vpcAPI
+type fakeVPC struct {
+	mu                sync.Mutex
+	networkInterfaces []*vpc.NetworkInterface
+	routeTables       []*vpc.RouteTable
+	vpcs              []*vpc.Vpc
+	subnets           []*vpc.Subnet
+	nextRouteId       uint64
+	calls             map[string]int
+	// describeNetworkInterfacesErr is returned by DescribeNetworkInterfaces when set
+	describeNetworkInterfacesErr error
+}
+
+func newFakeVPC() *fakeVPC {
+	return &fakeVPC{calls: make(map[string]int)}
+}
+
+// addRouteTable adds an empty route table to the test vpc
+func (f *fakeVPC) addRouteTable(routeTableId string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.routeTables = append(f.routeTables, &vpc.RouteTable{
+		VpcId:        common.StringPtr("vpc-test"),
+		RouteTableId: common.StringPtr(routeTableId),
+	})
+}
+
+// addVpc adds the test vpc with an ipv4 cidr and the subnets with their cidrs
+func (f *fakeVPC) addVpc(cidr string, subnetCidrs ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.vpcs = append(f.vpcs, &vpc.Vpc{VpcId: common.StringPtr("vpc-test"), CidrBlock: common.StringPtr(cidr)})
+	for idx, subnetCidr := range subnetCidrs {
+		f.subnets = append(f.subnets, &vpc.Subnet{
+			VpcId:     common.StringPtr("vpc-test"),
+			SubnetId:  common.StringPtr(fmt.Sprintf("subnet-%d", idx)),
+			CidrBlock: common.StringPtr(subnetCidr),
+		})
+	}
+}
+
+// routes returns the routes of routeTableId
+func (f *fakeVPC) routes(routeTableId string) []*vpc.Route {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, table := range f.routeTables {
+		if *table.RouteTableId == routeTableId {
+			return table.RouteSet
+		}
+	}
+	return nil
+}
+
+func (f *fakeVPC) callCount(action string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[action]
+}
+
+// addNetworkInterface attaches an ENI with the private ips to the instance
+func (f *fakeVPC) addNetworkInterface(instanceId string, primary bool, privateIps ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	addresses := make([]*vpc.PrivateIpAddressSpecification, 0)
+	for idx, ip := range privateIps {
+		addresses = append(addresses, &vpc.PrivateIpAddressSpecification{
+			PrivateIpAddress: common.StringPtr(ip),
+			Primary:          common.BoolPtr(idx == 0),
+		})
+	}
+	f.networkInterfaces = append(f.networkInterfaces, &vpc.NetworkInterface{
+		NetworkInterfaceId:  common.StringPtr("eni-" + instanceId),
+		VpcId:               common.StringPtr("vpc-test"),
+		Primary:             common.BoolPtr(primary),
+		PrivateIpAddressSet: addresses,
+		Attachment:          &vpc.NetworkInterfaceAttachment{InstanceId: common.StringPtr(instanceId)},
+	})
+}
+
+func (f *fakeVPC) DescribeNetworkInterfaces(request *vpc.DescribeNetworkInterfacesRequest) (*vpc.DescribeNetworkInterfacesResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls["DescribeNetworkInterfaces"]++
+	if f.describeNetworkInterfacesErr != nil {
+		return nil, f.describeNetworkInterfacesErr
+	}
+
+	set := make([]*vpc.NetworkInterface, 0)
+	for _, networkInterface := range f.networkInterfaces {
+		matched := true
+		for _, filter := range request.Filters {
+			if *filter.Name == "attachment.instance-id" {
+				matched = matched && containsString(common.StringValues(filter.Values), *networkInterface.Attachment.InstanceId)
+			}
+		}
+		if matched {
+			set = append(set, networkInterface)
+		}
+	}
+	response := vpc.NewDescribeNetworkInterfacesResponse()
+	fillResponse(response, map[string]interface{}{
+		"NetworkInterfaceSet": set,
+		"TotalCount":          len(set),
+		"RequestId":           "req-vpc",
+	})
+	return response, nil
+}
+
+func (f *fakeVPC) DescribeRouteTables(request *vpc.DescribeRouteTablesRequest) (*vpc.DescribeRouteTablesResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls["DescribeRouteTables"]++
+
+	set := make([]*vpc.RouteTable, 0)
+	for _, table := range f.routeTables {
+		if len(request.RouteTableIds) == 0 || containsString(common.StringValues(request.RouteTableIds), *table.RouteTableId) {
+			set = append(set, table)
+		}
+	}
+	response := vpc.NewDescribeRouteTablesResponse()
+	fillResponse(response, map[string]interface{}{
+		"RouteTableSet": set,
+		"TotalCount":    len(set),
+		"RequestId":     "req-vpc",
+	})
+	return response, nil
+}
+
+func (f *fakeVPC) routeTable(routeTableId string) (*vpc.RouteTable, error) {
+	for _, table := range f.routeTables {
+		if *table.RouteTableId == routeTableId {
+			return table, nil
+		}
+	}
+	return nil, errors.NewTencentCloudSDKError("InvalidParameterValue", "route table "+routeTableId+" not found", "req-vpc")
+}
+
+func (f *fakeVPC) CreateRoutes(request *vpc.CreateRoutesRequest) (*vpc.CreateRoutesResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls["CreateRoutes"]++
+
+	table, err := f.routeTable(*request.RouteTableId)
+	if err != nil {
+		return nil, err
+	}
+	for _, route := range request.Routes {
+		for _, existing := range table.RouteSet {
+			if routeDestination(existing) == routeDestination(route) {
+				return nil, errors.NewTencentCloudSDKError("InvalidParameterValue.Duplicate", "route "+routeDestination(route)+" exists", "req-vpc")
+			}
+		}
+		f.nextRouteId++
+		created := *route
+		// like the API, the RouteId of an ipv6 route is 0, only its RouteItemId identifies it
+		created.RouteId = common.Uint64Ptr(f.nextRouteId)
+		if route.DestinationIpv6CidrBlock != nil {
+			created.RouteId = common.Uint64Ptr(0)
+		}
+		created.RouteItemId = common.StringPtr(fmt.Sprintf("rti-%08d", f.nextRouteId))
+		created.RouteTableId = table.RouteTableId
+		created.RouteType = common.StringPtr("USER")
+		created.Enabled = common.BoolPtr(true)
+		table.RouteSet = append(table.RouteSet, &created)
+	}
+	response := vpc.NewCreateRoutesResponse()
+	fillResponse(response, map[string]interface{}{
+		"TotalCount":    len(request.Routes),
+		"RouteTableSet": []*vpc.RouteTable{table},
+		"RequestId":     "req-vpc",
+	})
+	return response, nil
+}
+
+func (f *fakeVPC) DeleteRoutes(request *vpc.DeleteRoutesRequest) (*vpc.DeleteRoutesResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls["DeleteRoutes"]++
+
+	table, err := f.routeTable(*request.RouteTableId)
+	if err != nil {
+		return nil, err
+	}
+	deleted := make([]*vpc.Route, 0)
+	for _, route := range request.Routes {
+		kept := make([]*vpc.Route, 0, len(table.RouteSet))
+		for _, existing := range table.RouteSet {
+			if *existing.RouteItemId == *route.RouteItemId {
+				deleted = append(deleted, existing)
+				continue
+			}
+			kept = append(kept, existing)
+		}
+		table.RouteSet = kept
+	}
+	response := vpc.NewDeleteRoutesResponse()
+	fillResponse(response, map[string]interface{}{
+		"RouteSet":  deleted,
+		"RequestId": "req-vpc",
+	})
+	return response, nil
+}
+
+func (f *fakeVPC) ReplaceRoutes(request *vpc.ReplaceRoutesRequest) (*vpc.ReplaceRoutesResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls["ReplaceRoutes"]++
+
+	table, err := f.routeTable(*request.RouteTableId)
+	if err != nil {
+		return nil, err
+	}
+	oldRoutes, newRoutes := make([]*vpc.Route, 0), make([]*vpc.Route, 0)
+	for _, route := range request.Routes {
+		for idx, existing := range table.RouteSet {
+			if *existing.RouteItemId != *route.RouteItemId {
+				continue
+			}
+			replaced := *route
+			replaced.RouteTableId = existing.RouteTableId
+			replaced.RouteType = existing.RouteType
+			replaced.Enabled = existing.Enabled
+			table.RouteSet[idx] = &replaced
+			oldRoutes, newRoutes = append(oldRoutes, existing), append(newRoutes, &replaced)
+		}
+	}
+	response := vpc.NewReplaceRoutesResponse()
+	fillResponse(response, map[string]interface{}{
+		"OldRouteSet": oldRoutes,
+		"NewRouteSet": newRoutes,
+		"RequestId":   "req-vpc",
+	})
+	return response, nil
+}
+
+func (f *fakeVPC) DescribeVpcs(request *vpc.DescribeVpcsRequest) (*vpc.DescribeVpcsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls["DescribeVpcs"]++
+
+	set := make([]*vpc.Vpc, 0)
+	for _, v := range f.vpcs {
+		if len(request.VpcIds) == 0 || containsString(common.StringValues(request.VpcIds), *v.VpcId) {
+			set = append(set, v)
+		}
+	}
+	response := vpc.NewDescribeVpcsResponse()
+	fillResponse(response, map[string]interface{}{
+		"VpcSet":     set,
+		"TotalCount": len(set),
+		"RequestId":  "req-vpc",
+	})
+	return response, nil
+}
+
+func (f *fakeVPC) DescribeSubnets(request *vpc.DescribeSubnetsRequest) (*vpc.DescribeSubnetsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls["DescribeSubnets"]++
+
+	set := make([]*vpc.Subnet, 0)
+	for _, subnet := range f.subnets {
+		matched := true
+		for _, filter := range request.Filters {
+			if *filter.Name == "vpc-id" {
+				matched = matched && containsString(common.StringValues(filter.Values), *subnet.VpcId)
+			}
+		}
+		if matched {
+			set = append(set, subnet)
+		}
+	}
+	total := len(set)
+	if request.Offset != nil {
+		offset, _ := strconv.Atoi(*request.Offset)
+		if offset < len(set) {
+			set = set[offset:]
+		} else {
+			set = nil
+		}
+	}
+	if request.Limit != nil {
+		if limit, _ := strconv.Atoi(*request.Limit); limit < len(set) {
+			set = set[:limit]
+		}
+	}
+	response := vpc.NewDescribeSubnetsResponse()
+	fillResponse(response, map[string]interface{}{
+		"SubnetSet":  set,
+		"TotalCount": total,
+		"RequestId":  "req-vpc",
+	})
+	return response, nil
+}
@@ -0,0 +1,122 @@
+package tencentcloud
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	cvm "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/cvm/v20170312"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog"
+)
+
+const (
+	// describeInstancesMaxLimit is the max page size of DescribeInstances
+	describeInstancesMaxLimit = 100
+	// describeInstancesMaxFilterValues is the max values count of a DescribeInstances filter
+	describeInstancesMaxFilterValues = 5
+
+	defaultInstanceInventoryInterval = 30 * time.Second
+)
+
+// runInstanceInventory refreshes the instance inventory every interval until stop is closed
+func (cloud *Cloud) runInstanceInventory(interval time.Duration, stop <-chan struct{}) {
+	klog.V(3).Infof("tencentcloud.runInstanceInventory: refresh every %v\n", interval)
+	wait.Until(func() {
+		if err := cloud.refreshInstanceInventory(context.TODO(), interval); err != nil {
+			klog.Warningf("tencentcloud.runInstanceInventory: refresh error: %v\n", err)
+		}
+	}, interval, stop)
+}
+
+// refreshInstanceInventory lists every instance of the vpc page by page and fills the instance cache by ip, id and name.
+// Entries live for two intervals so that a single failed refresh does not empty the cache,
+// entries of instances gone from the vpc are deleted once a refresh completes, unless a direct lookup
+// set them while the vpc was listed. The inventory takes about three cache entries per instance,
+// CacheMaxEntries below that evicts the entries of the inventory on every refresh.
+func (cloud *Cloud) refreshInstanceInventory(ctx context.Context, interval time.Duration) error {
+	klog.V(3).Infof("tencentcloud.refreshInstanceInventory(\"%s\"): entered\n", cloud.txConfig.VpcId)
+
+	version := cloud.instanceCache.Version()
+	instances, err := cloud.listVpcInstances(ctx)
+	if err != nil {
+		klog.V(3).Infof("tencentcloud.refreshInstanceInventory: return: %v\n", err)
+		return err
+	}
+
+	ttl := 2 * interval
+	seen := make(map[string]bool)
+	names := make(map[string]int)
+	for _, instance := range instances {
+		names[*instance.InstanceName]++
+	}
+	for _, instance := range instances {
+		// a shared instance name is ambiguous, its lookups fall back to a direct call
+		if names[*instance.InstanceName] == 1 {
+			cacheKey := cacheNamePreVmName + *instance.InstanceName
+			cloud.instanceCache.SetWithTTL(cacheKey, instance, ttl)
+			seen[cacheKey] = true
+		}
+		cacheKey := cacheNamePreVmID + *instance.InstanceId
+		cloud.instanceCache.SetWithTTL(cacheKey, instance, ttl)
+		seen[cacheKey] = true
+		for _, ip := range instance.PrivateIpAddresses {
+			cacheKey := cacheNamePreVmIp + *ip
+			cloud.instanceCache.SetWithTTL(cacheKey, instance, ttl)
+			seen[cacheKey] = true
+		}
+	}
+
+	if maxEntries := cloud.txConfig.CacheMaxEntries; maxEntries > 0 && len(seen) > maxEntries {
+		klog.Warningf("tencentcloud.refreshInstanceInventory: %d inventory cache keys exceed cache_max_entries %d, raise it above %d\n", len(seen), maxEntries, len(seen))
+	}
+
+	// negative entries are left to expire on their own, entries set since the listing started are newer than it
+	for cacheKey, instance := range cloud.instanceCache.Snapshot() {
+		if instance == nil || seen[cacheKey] {
+			continue
+		}
+		if strings.HasPrefix(cacheKey, cacheNamePreVmID) || strings.HasPrefix(cacheKey, cacheNamePreVmIp) ||
+			strings.HasPrefix(cacheKey, cacheNamePreVmName) {
+			cloud.instanceCache.DeleteIfSetBefore(cacheKey, version)
+		}
+	}
+	inventoryInstances.Set(float64(len(instances)))
+
+	klog.V(3).Infof("tencentcloud.refreshInstanceInventory: return: instances count %d, nil\n", len(instances))
+	return nil
+}
+
+// listVpcInstances returns every instance of the vpc
+func (cloud *Cloud) listVpcInstances(ctx context.Context) ([]*cvm.Instance, error) {
+	instances := make([]*cvm.Instance, 0)
+	for offset := int64(0); ; offset += describeInstancesMaxLimit {
+		request := cvm.NewDescribeInstancesRequest()
+		request.Filters = []*cvm.Filter{
+			{
+				Values: common.StringPtrs([]string{cloud.txConfig.VpcId}),
+				Name:   common.StringPtr("vpc-id"),
+			},
+		}
+		request.Offset = common.Int64Ptr(offset)
+		request.Limit = common.Int64Ptr(describeInstancesMaxLimit)
+
+		request.SetContext(ctx)
+		response, err := cloud.cvm.DescribeInstances(request)
+		if err != nil {
+			klog.Warningf("tencentcloud.listVpcInstances: tencentcloud API error: %v, offset: %d\n", err, offset)
+			return nil, err
+		}
+		for _, instance := range response.Response.InstanceSet {
+			if *instance.VirtualPrivateCloud.VpcId != cloud.txConfig.VpcId {
+				continue
+			}
+			instances = append(instances, instance)
+		}
+		if len(response.Response.InstanceSet) < describeInstancesMaxLimit ||
+			offset+describeInstancesMaxLimit >= *response.Response.TotalCount {
+			return instances, nil
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package tencentcloud
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	cvm "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/cvm/v20170312"
+)
+
+func TestRefreshInstanceInventory(t *testing.T) {
+	instances := make([]*cvm.Instance, 0)
+	for i := 0; i < 250; i++ {
+		instances = append(instances, newTestInstance(fmt.Sprintf("ins-%d", i), fmt.Sprintf("10.0.%d.%d", i/200, i%200)))
+	}
+	other := newTestInstance("ins-other", "10.1.0.1")
+	other.VirtualPrivateCloud.VpcId = common.StringPtr("vpc-other")
+	fakeCvm := newFakeCVM(append(instances, other)...)
+	cloud := newTestCloud(newFakeCLB(), fakeCvm)
+
+	if err := cloud.refreshInstanceInventory(context.Background(), time.Minute); err != nil {
+		t.Fatalf("unexpected refresh error: %v", err)
+	}
+	if calls := fakeCvm.callCount(); calls != 3 {
+		t.Errorf("DescribeInstances called %d times for 250 instances, want 3", calls)
+	}
+
+	// lookups are served from the inventory
+	for _, ip := range []string{"10.0.0.0", "10.0.0.199", "10.0.1.49"} {
+		if _, err := cloud.getInstanceByInstancePrivateIp(context.Background(), ip); err != nil {
+			t.Errorf("unexpected lookup error for %s: %v", ip, err)
+		}
+	}
+	if _, err := cloud.getInstanceByInstanceID(context.Background(), "ins-249"); err != nil {
+		t.Errorf("unexpected lookup error for ins-249: %v", err)
+	}
+	if calls := fakeCvm.callCount(); calls != 3 {
+		t.Errorf("DescribeInstances called %d times, want lookups served from the inventory", calls)
+	}
+
+	// unknown keys fall back to a direct call
+	if _, err := cloud.getInstanceByInstanceID(context.Background(), "ins-other"); err == nil {
+		t.Errorf("expected instance of another vpc not to be found")
+	}
+	if calls := fakeCvm.callCount(); calls != 4 {
+		t.Errorf("DescribeInstances called %d times, want a direct call for an unknown key", calls)
+	}
+}
+
+func TestRefreshInstanceInventoryDeletesGoneInstances(t *testing.T) {
+	fakeCvm := newFakeCVM(newTestInstance("ins-1", "10.0.0.1"), newTestInstance("ins-2", "10.0.0.2"))
+	cloud := newTestCloud(newFakeCLB(), fakeCvm)
+	if err := cloud.refreshInstanceInventory(context.Background(), time.Minute); err != nil {
+		t.Fatalf("unexpected refresh error: %v", err)
+	}
+
+	fakeCvm.mu.Lock()
+	fakeCvm.instances = fakeCvm.instances[:1]
+	fakeCvm.mu.Unlock()
+	if err := cloud.refreshInstanceInventory(context.Background(), time.Minute); err != nil {
+		t.Fatalf("unexpected refresh error: %v", err)
+	}
+
+	for _, cacheKey := range []string{cacheNamePreVmIp + "10.0.0.2", cacheNamePreVmID + "ins-2"} {
+		if _, ok := cloud.instanceCache.Get(cacheKey); ok {
+			t.Errorf("expected %s to be deleted from the cache", cacheKey)
+		}
+	}
+	if _, ok := cloud.instanceCache.Get(cacheNamePreVmIp + "10.0.0.1"); !ok {
+		t.Errorf("expected 10.0.0.1 to stay in the cache")
+	}
+}
+
+func TestRefreshInstanceInventoryKeepsConcurrentLookups(t *testing.T) {
+	fakeCvm := newFakeCVM(newTestInstance("ins-1", "10.0.0.1"))
+	cloud := newTestCloud(newFakeCLB(), fakeCvm)
+
+	gate := make(chan struct{})
+	fakeCvm.mu.Lock()
+	fakeCvm.gate = gate
+	fakeCvm.mu.Unlock()
+	done := make(chan error)
+	go func() {
+		done <- cloud.refreshInstanceInventory(context.Background(), time.Minute)
+	}()
+	for fakeCvm.callCount() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	// an instance created after its page was read, looked up directly during the listing
+	cloud.instanceCache.Set(cacheNamePreVmIp+"10.0.0.2", newTestInstance("ins-2", "10.0.0.2"))
+	close(gate)
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected refresh error: %v", err)
+	}
+
+	for _, cacheKey := range []string{cacheNamePreVmIp + "10.0.0.1", cacheNamePreVmIp + "10.0.0.2"} {
+		if _, ok := cloud.instanceCache.Get(cacheKey); !ok {
+			t.Errorf("expected %s to stay in the cache", cacheKey)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package tencentcloud
+
+import (
+	cvm "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/cvm/v20170312"
+	"k8s.io/klog"
+)
+
+// instanceStatus is what a CVM instance state means to the node lifecycle controller
+type instanceStatus int
+
+const (
+	instanceStatusRunning instanceStatus = iota
+	instanceStatusShutdown
+	instanceStatusNotExist
+)
+
+// instanceStateTable maps CVM InstanceState to instanceStatus, transient states count as running
+var instanceStateTable = map[string]instanceStatus{
+	"PENDING":       instanceStatusRunning,
+	"LAUNCH_FAILED": instanceStatusRunning,
+	"RUNNING":       instanceStatusRunning,
+	"STARTING":      instanceStatusRunning,
+	"STOPPING":      instanceStatusRunning,
+	"REBOOTING":     instanceStatusRunning,
+	"STOPPED":       instanceStatusShutdown,
+	"SHUTDOWN":      instanceStatusShutdown,
+	"TERMINATING":   instanceStatusNotExist,
+}
+
+// recycledIsolatedSources are the IsolatedSource of an instance moved to the recycle bin
+var recycledIsolatedSources = map[string]bool{
+	"ARREAR":  true,
+	"EXPIRE":  true,
+	"MANMADE": true,
+}
+
+// getInstanceStatus returns the instanceStatus of the instance, unknown states count as running
+func getInstanceStatus(instance *cvm.Instance) instanceStatus {
+	if instance.IsolatedSource != nil && recycledIsolatedSources[*instance.IsolatedSource] {
+		return instanceStatusNotExist
+	}
+	if instance.InstanceState == nil {
+		return instanceStatusRunning
+	}
+	status, ok := instanceStateTable[*instance.InstanceState]
+	if !ok {
+		klog.Warningf("tencentcloud.getInstanceStatus: instance %s has unknown state %s\n", *instance.InstanceId, *instance.InstanceState)
+		return instanceStatusRunning
+	}
+	return status
+}
@@ -0,0 +1,67 @@
+package tencentcloud
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+)
+
+func TestInstanceStateMapping(t *testing.T) {
+	testCases := []struct {
+		state          string
+		isolatedSource string
+		exists         bool
+		shutdown       bool
+	}{
+		{state: "RUNNING", exists: true},
+		{state: "PENDING", exists: true},
+		{state: "LAUNCH_FAILED", exists: true},
+		{state: "STARTING", exists: true},
+		{state: "STOPPING", exists: true},
+		{state: "REBOOTING", exists: true},
+		{state: "STOPPED", exists: true, shutdown: true},
+		{state: "SHUTDOWN", exists: true, shutdown: true},
+		{state: "TERMINATING", exists: false},
+		{state: "SHUTDOWN", isolatedSource: "MANMADE", exists: false},
+		{state: "SHUTDOWN", isolatedSource: "EXPIRE", exists: false},
+		{state: "SHUTDOWN", isolatedSource: "ARREAR", exists: false},
+		{state: "RUNNING", isolatedSource: "NOTISOLATED", exists: true},
+		{state: "SOMETHING_NEW", exists: true},
+	}
+	for _, testCase := range testCases {
+		instance := newTestInstance("ins-1", "10.0.0.1")
+		instance.InstanceState = common.StringPtr(testCase.state)
+		if testCase.isolatedSource != "" {
+			instance.IsolatedSource = common.StringPtr(testCase.isolatedSource)
+		}
+		cloud := newTestCloud(newFakeCLB(), newFakeCVM(instance))
+		providerID := "tencentcloud:///ap-guangzhou-3/ins-1"
+
+		exists, err := cloud.InstanceExistsByProviderID(context.Background(), providerID)
+		if err != nil || exists != testCase.exists {
+			t.Errorf("%s/%s: InstanceExistsByProviderID = %v, %v, want %v, nil", testCase.state, testCase.isolatedSource, exists, err, testCase.exists)
+		}
+		shutdown, err := cloud.InstanceShutdownByProviderID(context.Background(), providerID)
+		if err != nil || shutdown != testCase.shutdown {
+			t.Errorf("%s/%s: InstanceShutdownByProviderID = %v, %v, want %v, nil", testCase.state, testCase.isolatedSource, shutdown, err, testCase.shutdown)
+		}
+	}
+}
+
+func TestInstanceNotFound(t *testing.T) {
+	cloud := newTestCloud(newFakeCLB(), newFakeCVM())
+	providerID := "tencentcloud:///ap-guangzhou-3/ins-1"
+
+	exists, err := cloud.InstanceExistsByProviderID(context.Background(), providerID)
+	if err != nil || exists {
+		t.Errorf("InstanceExistsByProviderID = %v, %v, want false, nil", exists, err)
+	}
+	shutdown, err := cloud.InstanceShutdownByProviderID(context.Background(), providerID)
+	if err != nil || shutdown {
+		t.Errorf("InstanceShutdownByProviderID = %v, %v, want false, nil", shutdown, err)
+	}
+	if _, err := cloud.InstanceExistsByProviderID(context.Background(), "aws:///us-east-1a/i-1"); err == nil {
+		t.Errorf("expected an invalid providerID to return an error")
+	}
+}
@@ -2,8 +2,6 @@ package tencentcloud
 
 import (
 	"context"
-	"errors"
-	"fmt"
 	"sort"
 	"strings"
 
@@ -24,19 +22,13 @@ var (
 // NodeAddresses returns the addresses of the specified instance.
 func (cloud *Cloud) NodeAddresses(ctx context.Context, name types.NodeName) ([]v1.NodeAddress, error) {
 	klog.V(3).Infof("tencentcloud.NodeAddresses(\"%s\"): entered\n", string(name))
-	node, err := cloud.getInstanceByInstancePrivateIp(ctx, string(name))
+	node, err := cloud.getInstanceByRegisteredNodeName(ctx, name)
 	if err != nil {
 		klog.Warningf("tencentcloud.NodeAddresses: tencentcloud API error: %v\n", err)
 		klog.V(3).Infof("tencentcloud.NodeAddresses: return: {}, %v\n", err)
 		return []v1.NodeAddress{}, err
 	}
-	addresses := make([]v1.NodeAddress, len(node.PrivateIpAddresses)+len(node.PublicIpAddresses))
-	for idx, ip := range node.PrivateIpAddresses {
-		addresses[idx] = v1.NodeAddress{Type: v1.NodeInternalIP, Address: *ip}
-	}
-	for idx, ip := range node.PublicIpAddresses {
-		addresses[len(node.PrivateIpAddresses)+idx] = v1.NodeAddress{Type: v1.NodeExternalIP, Address: *ip}
-	}
+	addresses := cloud.getNodeAddresses(ctx, node, string(name))
 
 	klog.V(3).Infof("tencentcloud.NodeAddresses: return: %v, nil\n", addresses)
 	return addresses, nil
@@ -55,13 +47,7 @@ func (cloud *Cloud) NodeAddressesByProviderID(ctx context.Context, providerID st
 		klog.V(3).Infof("tencentcloud.NodeAddressesByProviderID: return: {}, %v\n", err)
 		return []v1.NodeAddress{}, err
 	}
-	addresses := make([]v1.NodeAddress, len(instance.PrivateIpAddresses)+len(instance.PublicIpAddresses))
-	for idx, ip := range instance.PrivateIpAddresses {
-		addresses[idx] = v1.NodeAddress{Type: v1.NodeInternalIP, Address: *ip}
-	}
-	for idx, ip := range instance.PublicIpAddresses {
-		addresses[len(instance.PrivateIpAddresses)+idx] = v1.NodeAddress{Type: v1.NodeExternalIP, Address: *ip}
-	}
+	addresses := cloud.getNodeAddresses(ctx, instance, "")
 
 	klog.V(3).Infof("tencentcloud.NodeAddressesByProviderID: return: %v, nil\n", addresses)
 	return addresses, nil
@@ -71,7 +57,7 @@ func (cloud *Cloud) NodeAddressesByProviderID(ctx context.Context, providerID st
 // Note that if the instance does not exist or is no longer running, we must return ("", cloudprovider.InstanceNotFound)
 func (cloud *Cloud) ExternalID(ctx context.Context, nodeName types.NodeName) (string, error) {
 	klog.V(3).Infof("tencentcloud.ExternalID(\"%s\"): entered\n", string(nodeName))
-	node, err := cloud.getInstanceByInstancePrivateIp(ctx, string(nodeName))
+	node, err := cloud.getInstanceByRegisteredNodeName(ctx, nodeName)
 	if err != nil {
 		klog.Warningf("tencentcloud.ExternalID: Get error: %v\n", err)
 		klog.V(3).Infof("tencentcloud.ExternalID: return: '', %v\n", err)
@@ -85,14 +71,15 @@ func (cloud *Cloud) ExternalID(ctx context.Context, nodeName types.NodeName) (st
 // InstanceID returns the cloud provider ID of the node with the specified NodeName.
 func (cloud *Cloud) InstanceID(ctx context.Context, nodeName types.NodeName) (string, error) {
 	klog.V(3).Infof("tencentcloud.InstanceID(\"%s\"): entered\n", string(nodeName))
-	node, err := cloud.getInstanceByInstancePrivateIp(ctx, string(nodeName))
+	node, err := cloud.getInstanceByRegisteredNodeName(ctx, nodeName)
 	if err != nil {
 		klog.Warningf("tencentcloud.InstanceID: Get error: %v\n", err)
 		klog.V(3).Infof("tencentcloud.InstanceID: return: '', %v\n", err)
 		return "", err
 	}
 
-	ret := fmt.Sprintf("/%s/%s", *node.Placement.Zone, *node.InstanceId)
+	// the cloud controller manager prefixes the instance id with "tencentcloud://"
+	ret := strings.TrimPrefix(getInstanceProviderID(node).String(), providerName+"://")
 	klog.V(3).Infof("tencentcloud.InstanceID: return: %s, nil\n", ret)
 	return ret, nil
 }
@@ -100,7 +87,7 @@ func (cloud *Cloud) InstanceID(ctx context.Context, nodeName types.NodeName) (st
 // InstanceType returns the type of the specified instance.
 func (cloud *Cloud) InstanceType(ctx context.Context, name types.NodeName) (string, error) {
 	klog.V(3).Infof("tencentcloud.InstanceType(\"%s\"): entered\n", string(name))
-	node, err := cloud.getInstanceByInstancePrivateIp(ctx, string(name))
+	node, err := cloud.getInstanceByRegisteredNodeName(ctx, name)
 	if err != nil {
 		klog.Warningf("tencentcloud.InstanceType: Get error: %v\n", err)
 		klog.V(3).Infof("tencentcloud.InstanceType: return: '', %v\n", err)
@@ -131,43 +118,90 @@ func (cloud *Cloud) AddSSHKeyToAllInstances(ctx context.Context, user string, ke
 	return cloudProvider.NotImplemented
 }
 
-// CurrentNodeName returns the name of the node we are currently running on
-// On most clouds (e.g. GCE) this is the hostname, so we provide the hostname
+// CurrentNodeName returns the name of the node we are currently running on, read from the metadata service
+// according to the node name strategy, the hostname strategy returns hostname
 func (cloud *Cloud) CurrentNodeName(ctx context.Context, hostname string) (types.NodeName, error) {
-	return types.NodeName(""), cloudProvider.NotImplemented
+	klog.V(3).Infof("tencentcloud.CurrentNodeName(\"%s\"): entered\n", hostname)
+	var name string
+	var err error
+	switch cloud.txConfig.NodeNameStrategy {
+	case NodeNameStrategyHostname:
+		name = hostname
+	case NodeNameStrategyInstanceID:
+		name, err = cloud.metadata.InstanceID(ctx)
+	case NodeNameStrategyInstanceName:
+		var instanceID string
+		if instanceID, err = cloud.metadata.InstanceID(ctx); err == nil {
+			var instance *cvm.Instance
+			if instance, err = cloud.getInstanceByInstanceID(ctx, instanceID); err == nil {
+				name = *instance.InstanceName
+			}
+		}
+	default:
+		name, err = cloud.metadata.PrivateIP(ctx)
+	}
+	if err != nil {
+		klog.Warningf("tencentcloud.CurrentNodeName: Get error: %v\n", err)
+		klog.V(3).Infof("tencentcloud.CurrentNodeName: return: \"\", %v\n", err)
+		return types.NodeName(""), err
+	}
+
+	klog.V(3).Infof("tencentcloud.CurrentNodeName: return: %s, nil\n", name)
+	return types.NodeName(name), nil
 }
 
 // InstanceExistsByProviderID returns true if the instance for the given provider id still is running.
 // If false is returned with no error, the instance will be immediately deleted by the cloud controller manager.
 func (cloud *Cloud) InstanceExistsByProviderID(ctx context.Context, providerID string) (bool, error) {
 	klog.V(3).Infof("tencentcloud.InstanceExistsByProviderID(\"%s\"): entered\n", providerID)
-	_, err := cloud.getInstanceByProviderID(ctx, providerID)
-	if err == cloudProvider.InstanceNotFound {
+	instance, err := cloud.getInstanceByProviderID(ctx, providerID)
+	exists, err := instanceExists(instance, err)
+	if err != nil {
 		klog.Warningf("tencentcloud.InstanceExistsByProviderID: Get error: %v\n", err)
-		klog.V(3).Infof("tencentcloud.InstanceExistsByProviderID: return: false, %v\n", err)
-		return false, err
 	}
 
-	klog.V(3).Infof("tencentcloud.InstanceExistsByProviderID: return: true, %v\n", err)
-	return true, err
+	klog.V(3).Infof("tencentcloud.InstanceExistsByProviderID: return: %v, %v\n", exists, err)
+	return exists, err
 }
 
 // InstanceShutdownByProviderID returns true if the instance is shutdown in cloudprovider
 func (cloud *Cloud) InstanceShutdownByProviderID(ctx context.Context, providerID string) (bool, error) {
 	klog.V(3).Infof("tencentcloud.InstanceShutdownByProviderID(\"%s\"): entered\n", providerID)
 	instance, err := cloud.getInstanceByProviderID(ctx, providerID)
+	shutdown, err := instanceShutdown(instance, err)
 	if err != nil {
 		klog.Warningf("tencentcloud.InstanceShutdownByProviderID: Get error: %v\n", err)
-		klog.V(3).Infof("tencentcloud.InstanceShutdownByProviderID: return: false, %v\n", err)
+	}
+
+	klog.V(3).Infof("tencentcloud.InstanceShutdownByProviderID: return: %v, %v\n", shutdown, err)
+	return shutdown, err
+}
+
+// instanceExists maps the result of an instance lookup to whether the instance exists, not found is false, nil
+func instanceExists(instance *cvm.Instance, err error) (bool, error) {
+	if err == cloudProvider.InstanceNotFound {
+		return false, nil
+	}
+	if err != nil {
 		return false, err
 	}
-	if *instance.InstanceState != "RUNNING" {
-		klog.V(3).Infof("tencentcloud.InstanceShutdownByProviderID: return: true, %v\n", err)
-		return true, err
+	return getInstanceStatus(instance) != instanceStatusNotExist, nil
+}
+
+// instanceShutdown maps the result of an instance lookup to whether the instance is shutdown, not found is false, nil
+func instanceShutdown(instance *cvm.Instance, err error) (bool, error) {
+	if err == cloudProvider.InstanceNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
 	}
+	return getInstanceStatus(instance) == instanceStatusShutdown, nil
+}
 
-	klog.V(3).Infof("tencentcloud.InstanceShutdownByProviderID: return: false, %v\n", err)
-	return false, err
+// getInstanceProviderID returns the providerID of the instance
+func getInstanceProviderID(instance *cvm.Instance) ProviderID {
+	return ProviderID{Zone: *instance.Placement.Zone, InstanceID: *instance.InstanceId}
 }
 
 // getInstanceByInstancePrivateIp returns Tencent Cloud Instance for private ip
@@ -175,30 +209,42 @@ func (cloud *Cloud) getInstanceByInstancePrivateIp(ctx context.Context, privateI
 	klog.V(3).Infof("tencentcloud.getInstanceByInstancePrivateIp(\"%s\"): entered\n", privateIp)
 
 	cacheKey := cacheNamePreVmIp + privateIp
-	cacheValue, exist := cloud.cache.Get(cacheKey)
+	cacheValue, exist := cloud.instanceCache.Get(cacheKey)
 	if exist {
-		klog.V(3).Infof("tencentcloud.getInstanceByInstancePrivateIp: cache return(ip:%s):  %T, nil\n", privateIp, cacheValue.(*cvm.Instance))
-		return cacheValue.(*cvm.Instance), nil
+		if cacheValue == nil {
+			klog.V(3).Infof("tencentcloud.getInstanceByInstancePrivateIp: negative cache return(ip:%s): nil, %v\n", privateIp, cloudProvider.InstanceNotFound)
+			return nil, cloudProvider.InstanceNotFound
+		}
+		klog.V(3).Infof("tencentcloud.getInstanceByInstancePrivateIp: cache return(ip:%s):  %T, nil\n", privateIp, cacheValue)
+		return cacheValue, nil
 	}
 
-	ips := []string{privateIp}
-	instances, err := cloud.getInstanceByInstancePrivateIps(ctx, ips)
+	// concurrent lookups of the same ip share one api call
+	value, err, _ := cloud.instanceLookups.Do(cacheKey, func() (interface{}, error) {
+		instances, err := cloud.getInstanceByInstancePrivateIps(ctx, []string{privateIp})
+		if err != nil {
+			return nil, err
+		}
+		for _, instance := range instances {
+			for _, ip := range instance.PrivateIpAddresses {
+				if *ip == privateIp {
+					return instance, nil
+				}
+			}
+		}
+		return nil, cloudProvider.InstanceNotFound
+	})
 	if err != nil {
-		klog.Warningf("tencentcloud.getInstanceByInstancePrivateIp: Get error: %v\n", err)
+		if err != cloudProvider.InstanceNotFound {
+			klog.Warningf("tencentcloud.getInstanceByInstancePrivateIp: Get error: %v\n", err)
+		}
 		klog.V(3).Infof("tencentcloud.getInstanceByInstancePrivateIp: return: nil, %v\n", err)
 		return nil, err
 	}
-	for _, instance := range instances {
-		for _, ip := range instance.PrivateIpAddresses {
-			if *ip == privateIp {
-				klog.V(3).Infof("tencentcloud.getInstanceByInstancePrivateIp: return(ip:%s): %T, nil\n", privateIp, *instance)
-				return instance, nil
-			}
-		}
-	}
 
-	klog.V(3).Infof("tencentcloud.getInstanceByInstancePrivateIp: return: nil, %v\n", cloudProvider.InstanceNotFound)
-	return nil, cloudProvider.InstanceNotFound
+	instance := value.(*cvm.Instance)
+	klog.V(3).Infof("tencentcloud.getInstanceByInstancePrivateIp: return(ip:%s): %T, nil\n", privateIp, *instance)
+	return instance, nil
 }
 
 // getInstanceByInstancePrivateIps returns Tencent Cloud Instance for multi private ip
@@ -209,10 +255,12 @@ func (cloud *Cloud) getInstanceByInstancePrivateIps(ctx context.Context, private
 	instances := make([]*cvm.Instance, 0)
 	for _, ip := range privateIps {
 		cacheKey := cacheNamePreVmIp + ip
-		cacheValue, exist := cloud.cache.Get(cacheKey)
+		cacheValue, exist := cloud.instanceCache.Get(cacheKey)
 		if exist {
-			klog.V(3).Infof("tencentcloud.getInstanceByInstancePrivateIps: cache exist(ip:%s):  %T\n", ip, cacheValue.(*cvm.Instance))
-			instances = append(instances, cacheValue.(*cvm.Instance))
+			klog.V(3).Infof("tencentcloud.getInstanceByInstancePrivateIps: cache exist(ip:%s):  %T\n", ip, cacheValue)
+			if cacheValue != nil {
+				instances = append(instances, cacheValue)
+			}
 			continue
 		}
 		ips = append(ips, ip)
@@ -220,13 +268,14 @@ func (cloud *Cloud) getInstanceByInstancePrivateIps(ctx context.Context, private
 	sort.Strings(ips)
 	klog.V(3).Infof("tencentcloud.getInstanceByInstancePrivateIps: ips: %v\n", ips)
 
+	found := make(map[string]bool)
 	count := len(ips)
 	requestIps := make([]string, 0)
 	for i := 0; i < count; i++ {
 		requestIps = append(requestIps, ips[i])
 		//klog.V(3).Infof("tencentcloud.getInstanceByInstancePrivateIps: ips: %v\n", ips)
 		//klog.V(3).Infof("tencentcloud.getInstanceByInstancePrivateIps: requestIps: %v, %d\n", requestIps, i)
-		if (i > 0 && (i+1)%5 == 0) || i == count-1 {
+		if (i+1)%describeInstancesMaxFilterValues == 0 || i == count-1 {
 			request := cvm.NewDescribeInstancesRequest()
 			request.Filters = []*cvm.Filter{
 				{
@@ -234,7 +283,9 @@ func (cloud *Cloud) getInstanceByInstancePrivateIps(ctx context.Context, private
 					Name:   common.StringPtr("private-ip-address"),
 				},
 			}
+			request.Limit = common.Int64Ptr(describeInstancesMaxLimit)
 
+			request.SetContext(ctx)
 			response, err := cloud.cvm.DescribeInstances(request)
 			if _, ok := err.(*cloudErrors.TencentCloudSDKError); ok {
 				klog.Warningf("tencentcloud.getInstanceByInstancePrivateIps: tencentcloud API error: %v, requestIps: %v\n", err, requestIps)
@@ -254,8 +305,9 @@ func (cloud *Cloud) getInstanceByInstancePrivateIps(ctx context.Context, private
 					if isExist(*privateIp, ips) {
 						klog.V(3).Infof("tencentcloud.getInstanceByInstancePrivateIps: get instance from tencentcloud API(ip:%s): %T\n", *privateIp, *instance)
 						cacheKey := cacheNamePreVmIp + *privateIp
-						cloud.cache.Set(cacheKey, instance)
+						cloud.instanceCache.Set(cacheKey, instance)
 						instances = append(instances, instance)
+						found[*privateIp] = true
 						continue
 					}
 				}
@@ -263,6 +315,11 @@ func (cloud *Cloud) getInstanceByInstancePrivateIps(ctx context.Context, private
 			requestIps = nil
 		}
 	}
+	for _, ip := range ips {
+		if !found[ip] {
+			cloud.instanceCache.SetWithTTL(cacheNamePreVmIp+ip, nil, NegativeTTLTime)
+		}
+	}
 
 	klog.V(3).Infof("tencentcloud.getInstanceByInstancePrivateIps: return: instances count %d, nil\n", len(instances))
 	return instances, nil
@@ -273,12 +330,29 @@ func (cloud *Cloud) getInstanceByInstanceID(ctx context.Context, instanceID stri
 	klog.V(3).Infof("tencentcloud.getInstanceByInstanceID(\"%s\"): entered\n", instanceID)
 
 	cacheKey := cacheNamePreVmID + instanceID
-	cacheValue, exist := cloud.cache.Get(cacheKey)
+	cacheValue, exist := cloud.instanceCache.Get(cacheKey)
 	if exist {
-		klog.V(3).Infof("tencentcloud.getInstanceByInstanceID: cache return(instanceID:%s):  %T, nil\n", instanceID, cacheValue.(*cvm.Instance))
-		return cacheValue.(*cvm.Instance), nil
+		if cacheValue == nil {
+			klog.V(3).Infof("tencentcloud.getInstanceByInstanceID: negative cache return(instanceID:%s): nil, %v\n", instanceID, cloudProvider.InstanceNotFound)
+			return nil, cloudProvider.InstanceNotFound
+		}
+		klog.V(3).Infof("tencentcloud.getInstanceByInstanceID: cache return(instanceID:%s):  %T, nil\n", instanceID, cacheValue)
+		return cacheValue, nil
+	}
+
+	// concurrent lookups of the same instance id share one api call
+	value, err, _ := cloud.instanceLookups.Do(cacheKey, func() (interface{}, error) {
+		return cloud.describeInstanceByInstanceID(ctx, instanceID)
+	})
+	if err != nil {
+		return nil, err
 	}
+	return value.(*cvm.Instance), nil
+}
 
+// describeInstanceByInstanceID calls DescribeInstances for instanceID and caches the result, InstanceNotFound included
+func (cloud *Cloud) describeInstanceByInstanceID(ctx context.Context, instanceID string) (*cvm.Instance, error) {
+	cacheKey := cacheNamePreVmID + instanceID
 	request := cvm.NewDescribeInstancesRequest()
 	request.Filters = []*cvm.Filter{
 		{
@@ -287,6 +361,7 @@ func (cloud *Cloud) getInstanceByInstanceID(ctx context.Context, instanceID stri
 		},
 	}
 
+	request.SetContext(ctx)
 	response, err := cloud.cvm.DescribeInstances(request)
 	if _, ok := err.(*cloudErrors.TencentCloudSDKError); ok {
 		klog.Warningf("tencentcloud.getInstanceByInstanceID: tencentcloud API error: %v\n", err)
@@ -303,32 +378,37 @@ func (cloud *Cloud) getInstanceByInstanceID(ctx context.Context, instanceID stri
 			continue
 		}
 		if instanceID == *instance.InstanceId {
-			cloud.cache.Set(cacheKey, instance)
+			cloud.instanceCache.Set(cacheKey, instance)
 			klog.V(3).Infof("tencentcloud.getInstanceByInstanceID: return(instanceID:%s):  %T, nil\n", instanceID, *instance)
 			return instance, nil
 		}
 	}
 
+	cloud.instanceCache.SetWithTTL(cacheKey, nil, NegativeTTLTime)
 	klog.V(3).Infof("tencentcloud.getInstanceByInstanceID: return:  nil, %v\n", cloudProvider.InstanceNotFound)
 	return nil, cloudProvider.InstanceNotFound
 }
 
-// getInstanceIdByProviderID returns the addresses of the specified instance.
+// getInstanceByProviderID returns Tencent Cloud Instance for providerID in any format ParseProviderID supports
 func (cloud *Cloud) getInstanceByProviderID(ctx context.Context, providerID string) (*cvm.Instance, error) {
 	klog.V(3).Infof("tencentcloud.getInstanceByProviderID(\"%s\"): entered\n", providerID)
-	id := strings.TrimPrefix(providerID, fmt.Sprintf("%s://", providerName))
-	parts := strings.Split(id, "/")
-	if len(parts) == 3 {
-		instance, err := cloud.getInstanceByInstanceID(ctx, parts[2])
-		if err != nil {
-			klog.V(3).Infof("tencentcloud.getInstanceByProviderID: return:  nil, %v\n", err)
-			return nil, err
-		}
-		klog.V(3).Infof("tencentcloud.getInstanceByProviderID: return: %v, nil\n", err)
-		return instance, nil
+	parsed, err := ParseProviderID(providerID)
+	if err != nil {
+		klog.V(3).Infof("tencentcloud.getInstanceByProviderID: return:  nil, %v\n", err)
+		return nil, err
+	}
+	if parsed.Legacy {
+		klog.V(3).Infof("tencentcloud.getInstanceByProviderID: legacy providerID %s\n", providerID)
 	}
 
-	err := errors.New(fmt.Sprintf("invalid format for providerId %s", providerID))
-	klog.V(3).Infof("tencentcloud.getInstanceByProviderID: return:  nil, %v\n", err)
-	return nil, err
+	instance, err := cloud.getInstanceByInstanceID(ctx, parsed.InstanceID)
+	if err != nil {
+		klog.V(3).Infof("tencentcloud.getInstanceByProviderID: return:  nil, %v\n", err)
+		return nil, err
+	}
+	if parsed.Zone != "" && parsed.Zone != *instance.Placement.Zone {
+		klog.Warningf("tencentcloud.getInstanceByProviderID: providerID %s zone mismatch, instance zone is %s\n", providerID, *instance.Placement.Zone)
+	}
+	klog.V(3).Infof("tencentcloud.getInstanceByProviderID: return: %v, nil\n", *instance.InstanceId)
+	return instance, nil
 }
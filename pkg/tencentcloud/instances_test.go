@@ -0,0 +1,104 @@
+package tencentcloud
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+	cloudProvider "k8s.io/cloud-provider"
+)
+
+func TestGetInstanceNegativeCache(t *testing.T) {
+	fakeCvm := newFakeCVM(newTestInstance("ins-1", "10.0.0.1"))
+	cloud := newTestCloud(newFakeCLB(), fakeCvm)
+	fakeClock := clock.NewFakeClock(time.Now())
+	cloud.clock = fakeClock
+	cloud.initCaches()
+
+	for i := 0; i < 3; i++ {
+		if _, err := cloud.getInstanceByInstancePrivateIp(context.Background(), "10.0.0.2"); err != cloudProvider.InstanceNotFound {
+			t.Fatalf("expected InstanceNotFound for unknown ip, got %v", err)
+		}
+		if _, err := cloud.getInstanceByInstanceID(context.Background(), "ins-2"); err != cloudProvider.InstanceNotFound {
+			t.Fatalf("expected InstanceNotFound for unknown instance id, got %v", err)
+		}
+	}
+	if calls := fakeCvm.callCount(); calls != 2 {
+		t.Errorf("DescribeInstances called %d times, want 2", calls)
+	}
+
+	// the negative entry expires, so a new instance is found after NegativeTTLTime
+	fakeCvm.mu.Lock()
+	fakeCvm.instances = append(fakeCvm.instances, newTestInstance("ins-2", "10.0.0.2"))
+	fakeCvm.mu.Unlock()
+	if _, err := cloud.getInstanceByInstancePrivateIp(context.Background(), "10.0.0.2"); err != cloudProvider.InstanceNotFound {
+		t.Fatalf("expected the cached InstanceNotFound before NegativeTTLTime, got %v", err)
+	}
+	fakeClock.Step(NegativeTTLTime + time.Second)
+	instance, err := cloud.getInstanceByInstancePrivateIp(context.Background(), "10.0.0.2")
+	if err != nil || *instance.InstanceId != "ins-2" {
+		t.Errorf("expected ins-2 once the negative entry expired, got %v, %v", instance, err)
+	}
+	if calls := fakeCvm.callCount(); calls != 3 {
+		t.Errorf("DescribeInstances called %d times, want 3 with the re-lookup", calls)
+	}
+}
+
+func TestGetInstanceByInstancePrivateIpsSkipsNegativeEntries(t *testing.T) {
+	fakeCvm := newFakeCVM(newTestInstance("ins-1", "10.0.0.1"))
+	cloud := newTestCloud(newFakeCLB(), fakeCvm)
+
+	instances, err := cloud.getInstanceByInstancePrivateIps(context.Background(), []string{"10.0.0.1", "10.0.0.2"})
+	if err != nil || len(instances) != 1 {
+		t.Fatalf("expected 1 instance, got %d, %v", len(instances), err)
+	}
+	instances, err = cloud.getInstanceByInstancePrivateIps(context.Background(), []string{"10.0.0.1", "10.0.0.2"})
+	if err != nil || len(instances) != 1 {
+		t.Fatalf("expected 1 cached instance, got %d, %v", len(instances), err)
+	}
+	if calls := fakeCvm.callCount(); calls != 1 {
+		t.Errorf("DescribeInstances called %d times, want 1", calls)
+	}
+}
+
+func TestGetInstanceDeduplicatesConcurrentLookups(t *testing.T) {
+	fakeCvm := newFakeCVM(newTestInstance("ins-1", "10.0.0.1"))
+	fakeCvm.gate = make(chan struct{})
+	cloud := newTestCloud(newFakeCLB(), fakeCvm)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, err := cloud.getInstanceByInstancePrivateIp(context.Background(), "10.0.0.1")
+			errs <- err
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := cloud.getInstanceByInstanceID(context.Background(), "ins-1")
+			errs <- err
+		}()
+	}
+
+	// let every lookup join the two in-flight calls before releasing them
+	for fakeCvm.callCount() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(fakeCvm.gate)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("unexpected lookup error: %v", err)
+		}
+	}
+	if calls := fakeCvm.callCount(); calls != 2 {
+		t.Errorf("DescribeInstances called %d times, want 2", calls)
+	}
+}
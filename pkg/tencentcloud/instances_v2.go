@@ -0,0 +1,88 @@
+package tencentcloud
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog"
+)
+
+// InstancesV2 mirrors cloudprovider.InstancesV2, which k8s.io/cloud-provider only ships from v0.20 on.
+// The methods of Cloud have the upstream signatures, once the kubernetes dependencies are bumped
+// InstancesV2 goes and InstanceMetadata becomes an alias of cloudprovider.InstanceMetadata.
+type InstancesV2 interface {
+	// InstanceExists returns true if the instance for the given node exists according to the cloud provider.
+	InstanceExists(ctx context.Context, node *v1.Node) (bool, error)
+	// InstanceShutdown returns true if the instance is shutdown according to the cloud provider.
+	InstanceShutdown(ctx context.Context, node *v1.Node) (bool, error)
+	// InstanceMetadata returns the instance's metadata.
+	InstanceMetadata(ctx context.Context, node *v1.Node) (*InstanceMetadata, error)
+}
+
+// InstanceMetadata mirrors cloudprovider.InstanceMetadata
+type InstanceMetadata struct {
+	// ProviderID is the full provider id of the node, e.g. tencentcloud:///ap-guangzhou-3/ins-xxx
+	ProviderID string
+	// InstanceType is the CVM instance type, e.g. S5.MEDIUM4
+	InstanceType string
+	// NodeAddresses are the addresses of the instance
+	NodeAddresses []v1.NodeAddress
+	// Zone is the availability zone of the instance
+	Zone string
+	// Region is the region of the instance
+	Region string
+}
+
+var _ InstancesV2 = &Cloud{}
+
+// InstancesV2 returns an InstancesV2 interface. Also returns true if the interface is supported, false otherwise.
+func (cloud *Cloud) InstancesV2() (InstancesV2, bool) {
+	return cloud, true
+}
+
+// InstanceExists returns true if the instance for the given node exists according to the cloud provider.
+func (cloud *Cloud) InstanceExists(ctx context.Context, node *v1.Node) (bool, error) {
+	klog.V(3).Infof("tencentcloud.InstanceExists(\"%s\"): entered\n", node.Name)
+	instance, err := cloud.getInstanceByNode(ctx, node)
+	exists, err := instanceExists(instance, err)
+	if err != nil {
+		klog.Warningf("tencentcloud.InstanceExists: Get error: %v\n", err)
+	}
+
+	klog.V(3).Infof("tencentcloud.InstanceExists: return: %v, %v\n", exists, err)
+	return exists, err
+}
+
+// InstanceShutdown returns true if the instance is shutdown according to the cloud provider.
+func (cloud *Cloud) InstanceShutdown(ctx context.Context, node *v1.Node) (bool, error) {
+	klog.V(3).Infof("tencentcloud.InstanceShutdown(\"%s\"): entered\n", node.Name)
+	instance, err := cloud.getInstanceByNode(ctx, node)
+	shutdown, err := instanceShutdown(instance, err)
+	if err != nil {
+		klog.Warningf("tencentcloud.InstanceShutdown: Get error: %v\n", err)
+	}
+
+	klog.V(3).Infof("tencentcloud.InstanceShutdown: return: %v, %v\n", shutdown, err)
+	return shutdown, err
+}
+
+// InstanceMetadata returns the provider id, instance type, addresses, zone and region of the node in one call.
+func (cloud *Cloud) InstanceMetadata(ctx context.Context, node *v1.Node) (*InstanceMetadata, error) {
+	klog.V(3).Infof("tencentcloud.InstanceMetadata(\"%s\"): entered\n", node.Name)
+	instance, err := cloud.getInstanceByNode(ctx, node)
+	if err != nil {
+		klog.Warningf("tencentcloud.InstanceMetadata: Get error: %v\n", err)
+		klog.V(3).Infof("tencentcloud.InstanceMetadata: return: nil, %v\n", err)
+		return nil, err
+	}
+
+	metadata := &InstanceMetadata{
+		ProviderID:    getInstanceProviderID(instance).String(),
+		InstanceType:  *instance.InstanceType,
+		NodeAddresses: cloud.getNodeAddresses(ctx, instance, node.Name),
+		Zone:          *instance.Placement.Zone,
+		Region:        cloud.txConfig.Region,
+	}
+	klog.V(3).Infof("tencentcloud.InstanceMetadata: return: %v, nil\n", *metadata)
+	return metadata, nil
+}
@@ -0,0 +1,94 @@
+package tencentcloud
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestInstancesV2(t *testing.T) {
+	stopped := newTestInstance("ins-2", "10.0.0.2")
+	stopped.InstanceState = common.StringPtr("STOPPED")
+	cloud := newTestCloud(newFakeCLB(), newFakeCVM(newTestInstance("ins-1", "10.0.0.1"), stopped))
+
+	byProviderID := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Spec:       v1.NodeSpec{ProviderID: "tencentcloud:///ap-guangzhou-3/ins-1"},
+	}
+	byInternalIP := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-2"},
+		Status: v1.NodeStatus{Addresses: []v1.NodeAddress{
+			{Type: v1.NodeHostName, Address: "node-2"},
+			{Type: v1.NodeInternalIP, Address: "10.0.0.2"},
+		}},
+	}
+	byName := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "10.0.0.1"}}
+	gone := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-3"},
+		Spec:       v1.NodeSpec{ProviderID: "tencentcloud:///ap-guangzhou-3/ins-3"},
+	}
+
+	testCases := []struct {
+		node     *v1.Node
+		exists   bool
+		shutdown bool
+	}{
+		{node: byProviderID, exists: true},
+		{node: byInternalIP, exists: true, shutdown: true},
+		{node: byName, exists: true},
+		{node: gone, exists: false},
+	}
+	for _, testCase := range testCases {
+		exists, err := cloud.InstanceExists(context.Background(), testCase.node)
+		if err != nil || exists != testCase.exists {
+			t.Errorf("%s: InstanceExists = %v, %v, want %v, nil", testCase.node.Name, exists, err, testCase.exists)
+		}
+		if !testCase.exists {
+			continue
+		}
+		shutdown, err := cloud.InstanceShutdown(context.Background(), testCase.node)
+		if err != nil || shutdown != testCase.shutdown {
+			t.Errorf("%s: InstanceShutdown = %v, %v, want %v, nil", testCase.node.Name, shutdown, err, testCase.shutdown)
+		}
+	}
+}
+
+func TestInstanceMetadata(t *testing.T) {
+	instance := newTestInstance("ins-1", "10.0.0.1")
+	instance.PublicIpAddresses = common.StringPtrs([]string{"1.1.1.1"})
+	cloud := newTestCloud(newFakeCLB(), newFakeCVM(instance))
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status:     v1.NodeStatus{Addresses: []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: "10.0.0.1"}}},
+	}
+
+	metadata, err := cloud.InstanceMetadata(context.Background(), node)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := &InstanceMetadata{
+		ProviderID:   "tencentcloud:///ap-guangzhou-3/ins-1",
+		InstanceType: "S5.MEDIUM4",
+		NodeAddresses: []v1.NodeAddress{
+			{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+			{Type: v1.NodeExternalIP, Address: "1.1.1.1"},
+			{Type: v1.NodeHostName, Address: "node-1"},
+		},
+		Zone:   "ap-guangzhou-3",
+		Region: "ap-guangzhou",
+	}
+	if !reflect.DeepEqual(metadata, expected) {
+		t.Errorf("InstanceMetadata = %+v, want %+v", metadata, expected)
+	}
+
+	// the provider id returned must resolve to the same instance
+	node.Spec.ProviderID = metadata.ProviderID
+	node.Status.Addresses = nil
+	if exists, err := cloud.InstanceExists(context.Background(), node); err != nil || !exists {
+		t.Errorf("InstanceExists by returned provider id = %v, %v, want true, nil", exists, err)
+	}
+}
@@ -0,0 +1,252 @@
+package tencentcloud
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	cvmext "github.com/weimob-tech/cloud-provider-tencent/pkg/cvm/v20170312"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	cloudProvider "k8s.io/cloud-provider"
+	"k8s.io/klog"
+)
+
+const (
+	// NodeMaintenanceScheduled is the condition of a node whose instance has a planned maintenance
+	NodeMaintenanceScheduled v1.NodeConditionType = "MaintenanceScheduled"
+	// NodeHostFailure is the condition of a node whose instance has a running, disk or network failure
+	NodeHostFailure v1.NodeConditionType = "HostFailure"
+
+	// MaintenanceScheduledTaintKey taints the nodes with the MaintenanceScheduled condition
+	MaintenanceScheduledTaintKey = "tencentcloud.com/maintenance-scheduled"
+	// HostFailureTaintKey taints the nodes with the HostFailure condition
+	HostFailureTaintKey = "tencentcloud.com/host-failure"
+
+	maintenanceEventReasonActive   = "CVMRepairTask"
+	maintenanceEventReasonInactive = "NoCVMRepairTask"
+
+	defaultMaintenanceEventsInterval = 60 * time.Second
+	describeTaskInfoMaxLimit         = 100
+)
+
+// repair task statuses of DescribeTaskInfo, a task is active until it is ended, canceled or avoided
+const (
+	repairTaskStatusPendingAuthorization = 1
+	repairTaskStatusProcessing           = 2
+	repairTaskStatusScheduled            = 4
+)
+
+// hostFailureTaskTypes are the repair task types of a failure, the other types are planned maintenances
+var hostFailureTaskTypes = map[uint64]bool{
+	102: true, // instance running exception
+	103: true, // instance disk exception
+	104: true, // instance network connection exception
+}
+
+// MaintenanceEventsConfig configures the sync of CVM repair tasks to node conditions and taints
+type MaintenanceEventsConfig struct {
+	// Enabled starts the sync loop
+	Enabled bool `json:"enabled"`
+	// Interval is the interval between two syncs, in seconds
+	Interval int `json:"interval"`
+}
+
+// nodeMaintenanceEvent is a node condition and the taint of the nodes it is true for
+type nodeMaintenanceEvent struct {
+	condition v1.NodeConditionType
+	taintKey  string
+}
+
+var nodeMaintenanceEvents = []nodeMaintenanceEvent{
+	{condition: NodeMaintenanceScheduled, taintKey: MaintenanceScheduledTaintKey},
+	{condition: NodeHostFailure, taintKey: HostFailureTaintKey},
+}
+
+// runMaintenanceEvents syncs node maintenance conditions every interval until stop is closed
+func (cloud *Cloud) runMaintenanceEvents(interval time.Duration, stop <-chan struct{}) {
+	klog.V(3).Infof("tencentcloud.runMaintenanceEvents: sync every %v\n", interval)
+	if !cloud.waitForNodeLister(stop) {
+		return
+	}
+	wait.Until(func() {
+		if err := cloud.syncMaintenanceEvents(context.TODO()); err != nil {
+			klog.Warningf("tencentcloud.runMaintenanceEvents: sync error: %v\n", err)
+		}
+	}, interval, stop)
+}
+
+// syncMaintenanceEvents sets the maintenance conditions and taints of every node to the active repair tasks of its instance
+func (cloud *Cloud) syncMaintenanceEvents(ctx context.Context) error {
+	klog.V(3).Infof("tencentcloud.syncMaintenanceEvents(): entered\n")
+	tasks, err := cloud.listActiveRepairTasks(ctx)
+	if err != nil {
+		return err
+	}
+	nodes, err := cloud.listNodes(ctx)
+	if err != nil {
+		klog.Warningf("tencentcloud.syncMaintenanceEvents: list nodes error: %v\n", err)
+		return err
+	}
+
+	for _, node := range nodes {
+		instanceID, err := cloud.getNodeInstanceID(ctx, node)
+		if err != nil && err != cloudProvider.InstanceNotFound {
+			klog.Warningf("tencentcloud.syncMaintenanceEvents: node %s Get error: %v\n", node.Name, err)
+			continue
+		}
+		// the node of a deleted instance has no active repair task, its conditions and taints are cleared
+		if err := cloud.syncNodeMaintenanceEvents(ctx, node, tasks[instanceID]); err != nil {
+			klog.Warningf("tencentcloud.syncMaintenanceEvents: node %s patch error: %v\n", node.Name, err)
+		}
+	}
+	return nil
+}
+
+// getNodeInstanceID returns the instance id of node, parsed from its providerID without a lookup when it has one
+func (cloud *Cloud) getNodeInstanceID(ctx context.Context, node *v1.Node) (string, error) {
+	if node.Spec.ProviderID != "" {
+		if parsed, err := ParseProviderID(node.Spec.ProviderID); err == nil {
+			return parsed.InstanceID, nil
+		}
+	}
+	instance, err := cloud.getInstanceByNode(ctx, node)
+	if err != nil {
+		return "", err
+	}
+	return *instance.InstanceId, nil
+}
+
+// listActiveRepairTasks returns the active repair tasks of every cvm instance, by instance id
+func (cloud *Cloud) listActiveRepairTasks(ctx context.Context) (map[string][]*cvmext.RepairTaskInfo, error) {
+	tasks := make(map[string][]*cvmext.RepairTaskInfo)
+	var offset uint64
+	for {
+		request := cvmext.NewDescribeTaskInfoRequest()
+		request.Product = common.StringPtr("CVM")
+		request.TaskStatus = common.Int64Ptrs([]int64{repairTaskStatusPendingAuthorization, repairTaskStatusProcessing, repairTaskStatusScheduled})
+		request.Offset = common.Uint64Ptr(offset)
+		request.Limit = common.Uint64Ptr(describeTaskInfoMaxLimit)
+		request.SetContext(ctx)
+
+		response, err := cloud.cvm.DescribeTaskInfo(request)
+		if err != nil {
+			klog.Warningf("tencentcloud.listActiveRepairTasks: tencentcloud API error: %v\n", err)
+			return nil, err
+		}
+		for _, task := range response.Response.RepairTaskInfoSet {
+			if task.InstanceId != nil {
+				tasks[*task.InstanceId] = append(tasks[*task.InstanceId], task)
+			}
+		}
+		offset += uint64(len(response.Response.RepairTaskInfoSet))
+		if len(response.Response.RepairTaskInfoSet) == 0 || response.Response.TotalCount == nil || offset >= *response.Response.TotalCount {
+			return tasks, nil
+		}
+	}
+}
+
+// syncNodeMaintenanceEvents updates the conditions and taints of node for the active repair tasks of its instance
+func (cloud *Cloud) syncNodeMaintenanceEvents(ctx context.Context, node *v1.Node, tasks []*cvmext.RepairTaskInfo) error {
+	now := metav1.Now()
+	updated := node.DeepCopy()
+	var statusChanged, specChanged bool
+	for _, event := range nodeMaintenanceEvents {
+		var messages []string
+		for _, task := range tasks {
+			if task.TaskId == nil || task.TaskTypeId == nil {
+				continue
+			}
+			if hostFailureTaskTypes[*task.TaskTypeId] != (event.condition == NodeHostFailure) {
+				continue
+			}
+			message := *task.TaskId
+			if task.TaskTypeName != nil {
+				message += " " + *task.TaskTypeName
+			}
+			messages = append(messages, message)
+		}
+		sort.Strings(messages)
+		active := len(messages) > 0
+
+		condition := v1.NodeCondition{
+			Type:               event.condition,
+			Status:             v1.ConditionFalse,
+			LastHeartbeatTime:  now,
+			LastTransitionTime: now,
+			Reason:             maintenanceEventReasonInactive,
+			Message:            "no active repair task",
+		}
+		if active {
+			condition.Status = v1.ConditionTrue
+			condition.Reason = maintenanceEventReasonActive
+			condition.Message = strings.Join(messages, ", ")
+		}
+		if setNodeCondition(updated, condition) {
+			statusChanged = true
+			if active {
+				cloud.eventRecorder.Eventf(node, v1.EventTypeWarning, string(event.condition), "Repair tasks of the instance: %s", condition.Message)
+			}
+		}
+
+		if active && !hasTaint(updated, event.taintKey) {
+			updated.Spec.Taints = append(updated.Spec.Taints, v1.Taint{Key: event.taintKey, Effect: v1.TaintEffectNoSchedule, TimeAdded: &now})
+			specChanged = true
+		} else if !active && hasTaint(updated, event.taintKey) {
+			updated.Spec.Taints = removeTaint(updated.Spec.Taints, event.taintKey)
+			specChanged = true
+		}
+	}
+
+	if statusChanged {
+		if err := cloud.patchNodeStatus(ctx, node, updated); err != nil {
+			return err
+		}
+		klog.V(3).Infof("tencentcloud.syncNodeMaintenanceEvents: node %s conditions patched\n", node.Name)
+	}
+	if specChanged {
+		if err := cloud.patchNode(ctx, node, updated); err != nil {
+			return err
+		}
+		klog.V(3).Infof("tencentcloud.syncNodeMaintenanceEvents: node %s taints patched\n", node.Name)
+	}
+	return nil
+}
+
+// setNodeCondition sets condition on node and returns true when its status or message changed,
+// a false condition the node does not have yet is not added
+func setNodeCondition(node *v1.Node, condition v1.NodeCondition) bool {
+	for i := range node.Status.Conditions {
+		existing := &node.Status.Conditions[i]
+		if existing.Type != condition.Type {
+			continue
+		}
+		if existing.Status == condition.Status && existing.Message == condition.Message {
+			return false
+		}
+		if existing.Status == condition.Status {
+			condition.LastTransitionTime = existing.LastTransitionTime
+		}
+		*existing = condition
+		return true
+	}
+	if condition.Status != v1.ConditionTrue {
+		return false
+	}
+	node.Status.Conditions = append(node.Status.Conditions, condition)
+	return true
+}
+
+// removeTaint returns taints without the taints with key
+func removeTaint(taints []v1.Taint, key string) []v1.Taint {
+	kept := make([]v1.Taint, 0, len(taints))
+	for _, taint := range taints {
+		if taint.Key != key {
+			kept = append(kept, taint)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,88 @@
+package tencentcloud
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestSyncMaintenanceEvents(t *testing.T) {
+	fakeCvm := newFakeCVM(newTestInstance("ins-1", "10.0.0.1"), newTestInstance("ins-2", "10.0.0.2"))
+	fakeCvm.addRepairTask("rep-1", "ins-1", 107, repairTaskStatusScheduled)
+	fakeCvm.addRepairTask("rep-2", "ins-2", 104, repairTaskStatusProcessing)
+	fakeCvm.addRepairTask("rep-3", "ins-2", 101, 3)
+	cloud := newTestCloud(newFakeCLB(), fakeCvm)
+	cloud.eventRecorder = record.NewFakeRecorder(10)
+	cloud.kubeClient = fake.NewSimpleClientset(
+		&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "10.0.0.1"}},
+		&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "10.0.0.2"}},
+		// the instance of 10.0.0.3 is gone, the condition and taint of its last repair task are stale
+		&v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "10.0.0.3"},
+			Spec: v1.NodeSpec{Taints: []v1.Taint{
+				{Key: "dedicated", Value: "gpu", Effect: v1.TaintEffectNoSchedule},
+				{Key: MaintenanceScheduledTaintKey, Effect: v1.TaintEffectNoSchedule},
+			}},
+			Status: v1.NodeStatus{Conditions: []v1.NodeCondition{
+				{Type: v1.NodeReady, Status: v1.ConditionTrue},
+				{Type: NodeMaintenanceScheduled, Status: v1.ConditionTrue, Reason: maintenanceEventReasonActive},
+			}},
+		},
+	)
+
+	check := func(step, name string, maintenance, failure bool) {
+		node, err := cloud.kubeClient.CoreV1().Nodes().Get(context.Background(), name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", step, err)
+		}
+		for _, event := range []struct {
+			condition v1.NodeConditionType
+			taintKey  string
+			active    bool
+		}{
+			{condition: NodeMaintenanceScheduled, taintKey: MaintenanceScheduledTaintKey, active: maintenance},
+			{condition: NodeHostFailure, taintKey: HostFailureTaintKey, active: failure},
+		} {
+			if hasTaint(node, event.taintKey) != event.active {
+				t.Errorf("%s: node %s taint %s = %v, want %v", step, name, event.taintKey, !event.active, event.active)
+			}
+			var status v1.ConditionStatus
+			for _, condition := range node.Status.Conditions {
+				if condition.Type == event.condition {
+					status = condition.Status
+				}
+			}
+			if (status == v1.ConditionTrue) != event.active {
+				t.Errorf("%s: node %s condition %s = %q, want active %v", step, name, event.condition, status, event.active)
+			}
+		}
+	}
+
+	if err := cloud.syncMaintenanceEvents(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	check("scheduled", "10.0.0.1", true, false)
+	check("scheduled", "10.0.0.2", false, true)
+	check("instance gone", "10.0.0.3", false, false)
+	node, err := cloud.kubeClient.CoreV1().Nodes().Get(context.Background(), "10.0.0.3", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// the patches keep the taints and conditions of others
+	if !hasTaint(node, "dedicated") || getNodeCondition(node, v1.NodeReady) == nil {
+		t.Errorf("node 10.0.0.3 = %+v, want the dedicated taint and the Ready condition kept", node)
+	}
+
+	// the conditions and taints are cleared once the tasks ended
+	fakeCvm.setRepairTaskStatus("rep-1", 3)
+	fakeCvm.setRepairTaskStatus("rep-2", 3)
+	if err := cloud.syncMaintenanceEvents(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	check("ended", "10.0.0.1", false, false)
+	check("ended", "10.0.0.2", false, false)
+}
@@ -0,0 +1,138 @@
+package tencentcloud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"k8s.io/klog"
+)
+
+const (
+	// DefaultMetadataBaseURL is the CVM instance metadata service, metadata.tencentyun.com, reachable from the instance itself
+	DefaultMetadataBaseURL = "http://169.254.0.23/latest/meta-data/"
+	// DefaultMetadataTimeout bounds every metadata request, the service answers in milliseconds on a CVM
+	DefaultMetadataTimeout = 2 * time.Second
+
+	metadataPathInstanceID          = "instance-id"
+	metadataPathLocalIPv4           = "local-ipv4"
+	metadataPathZone                = "placement/zone"
+	metadataPathRegion              = "placement/region"
+	metadataPathSpotTerminationTime = "spot/termination-time"
+)
+
+var (
+	// ErrNotOnCVM is returned when the metadata service can not be reached, i.e. we are not running on a CVM
+	ErrNotOnCVM = errors.New("not running on a tencentcloud CVM: metadata service unreachable")
+	// errMetadataNotFound is returned for a metadata path the service has no value for
+	errMetadataNotFound = errors.New("metadata not found")
+)
+
+// MetadataClient reads the metadata service of the CVM we are running on
+type MetadataClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// InstanceIdentity is the identity of the CVM we are running on
+type InstanceIdentity struct {
+	InstanceID string
+	PrivateIP  string
+	Zone       string
+	Region     string
+}
+
+// NewMetadataClient creates a MetadataClient, baseURL and timeout default to DefaultMetadataBaseURL and DefaultMetadataTimeout
+func NewMetadataClient(baseURL string, timeout time.Duration) *MetadataClient {
+	if baseURL == "" {
+		baseURL = DefaultMetadataBaseURL
+	}
+	if timeout <= 0 {
+		timeout = DefaultMetadataTimeout
+	}
+	return &MetadataClient{
+		baseURL:    strings.TrimSuffix(baseURL, "/") + "/",
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// Get returns the value of path, ErrNotOnCVM when the service can not be reached
+func (c *MetadataClient) Get(ctx context.Context, path string) (string, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return "", err
+	}
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		klog.Warningf("tencentcloud.MetadataClient.Get: Get error: %v, path: %s\n", err, path)
+		return "", fmt.Errorf("%w: %v", ErrNotOnCVM, err)
+	}
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return "", err
+	}
+	if response.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("metadata %s: %w", path, errMetadataNotFound)
+	}
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("metadata %s: unexpected status %d", path, response.StatusCode)
+	}
+	return strings.TrimSpace(string(body)), nil
+}
+
+// InstanceID returns the instance id, e.g. ins-xxxxxxxx
+func (c *MetadataClient) InstanceID(ctx context.Context) (string, error) {
+	return c.Get(ctx, metadataPathInstanceID)
+}
+
+// PrivateIP returns the primary private ipv4 address
+func (c *MetadataClient) PrivateIP(ctx context.Context) (string, error) {
+	return c.Get(ctx, metadataPathLocalIPv4)
+}
+
+// Zone returns the availability zone, e.g. ap-guangzhou-3
+func (c *MetadataClient) Zone(ctx context.Context) (string, error) {
+	return c.Get(ctx, metadataPathZone)
+}
+
+// Identity returns the instance id, private ip, zone and region
+func (c *MetadataClient) Identity(ctx context.Context) (InstanceIdentity, error) {
+	var identity InstanceIdentity
+	for path, value := range map[string]*string{
+		metadataPathInstanceID: &identity.InstanceID,
+		metadataPathLocalIPv4:  &identity.PrivateIP,
+		metadataPathZone:       &identity.Zone,
+		metadataPathRegion:     &identity.Region,
+	} {
+		v, err := c.Get(ctx, path)
+		if err != nil {
+			return InstanceIdentity{}, err
+		}
+		*value = v
+	}
+	return identity, nil
+}
+
+// checkIdentity check the CVM we are running on is in the configured region and vpc
+func (cloud *Cloud) checkIdentity(ctx context.Context) error {
+	identity, err := cloud.metadata.Identity(ctx)
+	if err != nil {
+		return err
+	}
+	if identity.Region != cloud.txConfig.Region {
+		return fmt.Errorf("instance %s is in region %s, not in the configured region %s", identity.InstanceID, identity.Region, cloud.txConfig.Region)
+	}
+	instance, err := cloud.getInstanceByInstanceID(ctx, identity.InstanceID)
+	if err != nil {
+		return err
+	}
+	if *instance.VirtualPrivateCloud.VpcId != cloud.txConfig.VpcId {
+		return fmt.Errorf("instance %s is in vpc %s, not in the configured vpc %s", identity.InstanceID, *instance.VirtualPrivateCloud.VpcId, cloud.txConfig.VpcId)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package tencentcloud
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// newFakeMetadataServer serves values by metadata path
+func newFakeMetadataServer(values map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		value, ok := values[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(value + "\n"))
+	}))
+}
+
+func TestCurrentNodeName(t *testing.T) {
+	server := newFakeMetadataServer(map[string]string{
+		"/latest/meta-data/instance-id":      "ins-1",
+		"/latest/meta-data/local-ipv4":       "10.0.0.1",
+		"/latest/meta-data/placement/zone":   "ap-guangzhou-3",
+		"/latest/meta-data/placement/region": "ap-guangzhou",
+	})
+	defer server.Close()
+	instance := newTestInstance("ins-1", "10.0.0.1")
+	instance.InstanceName = common.StringPtr("node-a")
+
+	testCases := []struct {
+		strategy string
+		expected types.NodeName
+	}{
+		{strategy: "", expected: "10.0.0.1"},
+		{strategy: NodeNameStrategyPrivateIP, expected: "10.0.0.1"},
+		{strategy: NodeNameStrategyInstanceID, expected: "ins-1"},
+		{strategy: NodeNameStrategyInstanceName, expected: "node-a"},
+		{strategy: NodeNameStrategyHostname, expected: "host-a"},
+	}
+	for _, testCase := range testCases {
+		cloud := newTestCloud(newFakeCLB(), newFakeCVM(instance))
+		cloud.metadata = NewMetadataClient(server.URL+"/latest/meta-data/", time.Second)
+		cloud.txConfig.NodeNameStrategy = testCase.strategy
+
+		name, err := cloud.CurrentNodeName(context.Background(), "host-a")
+		if err != nil || name != testCase.expected {
+			t.Errorf("%q: CurrentNodeName = %q, %v, want %q, nil", testCase.strategy, name, err, testCase.expected)
+		}
+	}
+
+	cloud := newTestCloud(newFakeCLB(), newFakeCVM(instance))
+	cloud.metadata = NewMetadataClient(server.URL+"/latest/meta-data/", time.Second)
+	if err := cloud.checkIdentity(context.Background()); err != nil {
+		t.Errorf("checkIdentity = %v, want nil", err)
+	}
+	cloud.txConfig.VpcId = "vpc-other"
+	if err := cloud.checkIdentity(context.Background()); err == nil {
+		t.Errorf("checkIdentity in another vpc = nil, want an error")
+	}
+}
+
+func TestMetadataClientNotOnCVM(t *testing.T) {
+	// a closed server refuses connections like a host without the metadata service
+	server := newFakeMetadataServer(nil)
+	server.Close()
+	client := NewMetadataClient(server.URL, time.Second)
+	if _, err := client.InstanceID(context.Background()); !errors.Is(err, ErrNotOnCVM) {
+		t.Errorf("InstanceID = %v, want ErrNotOnCVM", err)
+	}
+
+	// a server slower than the timeout
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer slow.Close()
+	client = NewMetadataClient(slow.URL, 50*time.Millisecond)
+	if _, err := client.Zone(context.Background()); !errors.Is(err, ErrNotOnCVM) {
+		t.Errorf("Zone = %v, want ErrNotOnCVM", err)
+	}
+
+	// a missing path is not ErrNotOnCVM
+	missing := newFakeMetadataServer(nil)
+	defer missing.Close()
+	client = NewMetadataClient(missing.URL, time.Second)
+	if _, err := client.Get(context.Background(), metadataPathSpotTerminationTime); !errors.Is(err, errMetadataNotFound) {
+		t.Errorf("Get = %v, want errMetadataNotFound", err)
+	}
+}
@@ -0,0 +1,59 @@
+package tencentcloud
+
+import (
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+const metricsNamespace = "tencentcloud"
+
+var (
+	// apiRetriesTotal counts tencentcloud api calls retried after a retryable error
+	apiRetriesTotal = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Namespace:      metricsNamespace,
+			Subsystem:      "api",
+			Name:           "retries_total",
+			Help:           "Number of tencentcloud api calls retried, partitioned by service, action and error code.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"service", "action", "code"},
+	)
+
+	// inventoryInstances is the instance count of the last successful inventory refresh
+	inventoryInstances = metrics.NewGauge(
+		&metrics.GaugeOpts{
+			Namespace:      metricsNamespace,
+			Subsystem:      "instance_inventory",
+			Name:           "instances",
+			Help:           "Number of instances in the vpc found by the last successful inventory refresh.",
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
+
+	// routeHealthMissingRoutes is the number of node pod CIDRs without a route found by the last route health check
+	routeHealthMissingRoutes = metrics.NewGauge(
+		&metrics.GaugeOpts{
+			Namespace:      metricsNamespace,
+			Subsystem:      "route_health",
+			Name:           "missing_routes",
+			Help:           "Number of node pod CIDRs without a route in the route table found by the last route health check.",
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
+
+	// routeHealthExtraRoutes is the number of routes of the cluster to no node pod CIDR found by the last route health check
+	routeHealthExtraRoutes = metrics.NewGauge(
+		&metrics.GaugeOpts{
+			Namespace:      metricsNamespace,
+			Subsystem:      "route_health",
+			Name:           "extra_routes",
+			Help:           "Number of routes of the cluster matching no node pod CIDR found by the last route health check.",
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
+)
+
+func init() {
+	legacyregistry.MustRegister(apiRetriesTotal, inventoryInstances, routeHealthMissingRoutes, routeHealthExtraRoutes)
+}
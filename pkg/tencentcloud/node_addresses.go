@@ -0,0 +1,117 @@
+package tencentcloud
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	cvm "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/cvm/v20170312"
+	vpc "github.com/weimob-tech/cloud-provider-tencent/pkg/vpc/v20170312"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/klog"
+)
+
+// ip families of NodeAddressIPFamilyOrder
+const (
+	NodeAddressIPFamilyIPv4 = "ipv4"
+	NodeAddressIPFamilyIPv6 = "ipv6"
+)
+
+// getNodeAddresses returns the addresses of the instance, nodeName may be empty when unknown.
+// InternalIPs come first, led by the primary private ip of NodeAddressIPFamilyOrder, then ExternalIPs,
+// then the Hostname and InternalDNS. The secondary ENI ips are left out when they can not be listed.
+func (cloud *Cloud) getNodeAddresses(ctx context.Context, instance *cvm.Instance, nodeName string) []v1.NodeAddress {
+	ipv4 := make([]v1.NodeAddress, 0)
+	ipv6 := make([]v1.NodeAddress, 0)
+	seen := make(map[string]bool)
+	add := func(addressType v1.NodeAddressType, address string) {
+		if address == "" || seen[address] {
+			return
+		}
+		seen[address] = true
+		if ip := net.ParseIP(address); ip != nil && ip.To4() == nil {
+			ipv6 = append(ipv6, v1.NodeAddress{Type: addressType, Address: address})
+			return
+		}
+		ipv4 = append(ipv4, v1.NodeAddress{Type: addressType, Address: address})
+	}
+
+	for _, ip := range instance.PrivateIpAddresses {
+		add(v1.NodeInternalIP, *ip)
+	}
+	for _, ip := range instance.IPv6Addresses {
+		add(v1.NodeInternalIP, *ip)
+	}
+	if cloud.txConfig.NodeAddressSecondaryENI {
+		ips, err := cloud.getInstanceENIPrivateIps(ctx, *instance.InstanceId)
+		if err != nil {
+			klog.Warningf("tencentcloud.getNodeAddresses: instance %s secondary ENI ips skipped: %v\n", *instance.InstanceId, err)
+		}
+		for _, ip := range ips {
+			add(v1.NodeInternalIP, ip)
+		}
+	}
+
+	addresses := make([]v1.NodeAddress, 0, len(ipv4)+len(ipv6)+len(instance.PublicIpAddresses)+2)
+	if cloud.txConfig.NodeAddressIPFamilyOrder == NodeAddressIPFamilyIPv6 {
+		addresses = append(append(addresses, ipv6...), ipv4...)
+	} else {
+		addresses = append(append(addresses, ipv4...), ipv6...)
+	}
+	for _, ip := range instance.PublicIpAddresses {
+		addresses = append(addresses, v1.NodeAddress{Type: v1.NodeExternalIP, Address: *ip})
+	}
+
+	hostname := getNodeHostname(nodeName)
+	if hostname != "" {
+		addresses = append(addresses, v1.NodeAddress{Type: v1.NodeHostName, Address: hostname})
+		if cloud.txConfig.NodeNameStrategy == NodeNameStrategyHostname && hostname == nodeName {
+			// the node name resolves to the private ip by definition of the strategy
+			addresses = append(addresses, v1.NodeAddress{Type: v1.NodeInternalDNS, Address: hostname})
+		} else if suffix := strings.Trim(cloud.txConfig.NodeInternalDNSSuffix, "."); suffix != "" {
+			addresses = append(addresses, v1.NodeAddress{Type: v1.NodeInternalDNS, Address: hostname + "." + suffix})
+		}
+	}
+	return addresses
+}
+
+// getNodeHostname returns the node name, empty when the node name is unknown, an ip or not a valid dns subdomain
+func getNodeHostname(nodeName string) string {
+	if nodeName == "" || net.ParseIP(nodeName) != nil || len(validation.IsDNS1123Subdomain(nodeName)) > 0 {
+		return ""
+	}
+	return nodeName
+}
+
+// getInstanceENIPrivateIps returns the private ips of every ENI attached to the instance
+func (cloud *Cloud) getInstanceENIPrivateIps(ctx context.Context, instanceId string) ([]string, error) {
+	klog.V(3).Infof("tencentcloud.getInstanceENIPrivateIps(\"%s\"): entered\n", instanceId)
+	request := vpc.NewDescribeNetworkInterfacesRequest()
+	request.Filters = []*vpc.Filter{
+		{
+			Name:   common.StringPtr("attachment.instance-id"),
+			Values: common.StringPtrs([]string{instanceId}),
+		},
+	}
+	request.Limit = common.Uint64Ptr(describeInstancesMaxLimit)
+
+	request.SetContext(ctx)
+	response, err := cloud.vpc.DescribeNetworkInterfaces(request)
+	if err != nil {
+		klog.Warningf("tencentcloud.getInstanceENIPrivateIps: tencentcloud API error: %v\n", err)
+		return nil, err
+	}
+	ips := make([]string, 0)
+	for _, networkInterface := range response.Response.NetworkInterfaceSet {
+		for _, address := range networkInterface.PrivateIpAddressSet {
+			ips = append(ips, *address.PrivateIpAddress)
+		}
+		for _, address := range networkInterface.Ipv6AddressSet {
+			ips = append(ips, *address.Address)
+		}
+	}
+	klog.V(3).Infof("tencentcloud.getInstanceENIPrivateIps: return: %v, nil\n", ips)
+	return ips, nil
+}
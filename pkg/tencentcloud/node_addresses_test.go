@@ -0,0 +1,114 @@
+package tencentcloud
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestGetNodeAddresses(t *testing.T) {
+	instance := newTestInstance("ins-1", "10.0.0.1")
+	instance.InstanceName = common.StringPtr("Worker-1")
+	instance.PublicIpAddresses = common.StringPtrs([]string{"1.1.1.1"})
+	instance.IPv6Addresses = common.StringPtrs([]string{"fd00::1"})
+	fakeVpc := newFakeVPC()
+	fakeVpc.addNetworkInterface("ins-1", true, "10.0.0.1", "10.0.0.11")
+	fakeVpc.addNetworkInterface("ins-1", false, "10.0.1.1")
+
+	testCases := []struct {
+		name     string
+		config   TxCloudConfig
+		nodeName string
+		expected []v1.NodeAddress
+	}{
+		{
+			name:     "default",
+			nodeName: "10.0.0.1",
+			expected: []v1.NodeAddress{
+				{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+				{Type: v1.NodeInternalIP, Address: "fd00::1"},
+				{Type: v1.NodeExternalIP, Address: "1.1.1.1"},
+			},
+		},
+		{
+			name:     "ipv6 first with secondary ENI",
+			config:   TxCloudConfig{NodeAddressIPFamilyOrder: NodeAddressIPFamilyIPv6, NodeAddressSecondaryENI: true},
+			nodeName: "10.0.0.1",
+			expected: []v1.NodeAddress{
+				{Type: v1.NodeInternalIP, Address: "fd00::1"},
+				{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+				{Type: v1.NodeInternalIP, Address: "10.0.0.11"},
+				{Type: v1.NodeInternalIP, Address: "10.0.1.1"},
+				{Type: v1.NodeExternalIP, Address: "1.1.1.1"},
+			},
+		},
+		{
+			name:     "hostname strategy",
+			config:   TxCloudConfig{NodeNameStrategy: NodeNameStrategyHostname},
+			nodeName: "worker-1.cluster.local",
+			expected: []v1.NodeAddress{
+				{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+				{Type: v1.NodeInternalIP, Address: "fd00::1"},
+				{Type: v1.NodeExternalIP, Address: "1.1.1.1"},
+				{Type: v1.NodeHostName, Address: "worker-1.cluster.local"},
+				{Type: v1.NodeInternalDNS, Address: "worker-1.cluster.local"},
+			},
+		},
+		{
+			name:     "internal dns suffix",
+			config:   TxCloudConfig{NodeInternalDNSSuffix: "vpc.internal."},
+			nodeName: "worker-1",
+			expected: []v1.NodeAddress{
+				{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+				{Type: v1.NodeInternalIP, Address: "fd00::1"},
+				{Type: v1.NodeExternalIP, Address: "1.1.1.1"},
+				{Type: v1.NodeHostName, Address: "worker-1"},
+				{Type: v1.NodeInternalDNS, Address: "worker-1.vpc.internal"},
+			},
+		},
+		{
+			// the instance name is no node name, it is not a hostname of the node
+			name: "unknown node name",
+			expected: []v1.NodeAddress{
+				{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+				{Type: v1.NodeInternalIP, Address: "fd00::1"},
+				{Type: v1.NodeExternalIP, Address: "1.1.1.1"},
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		cloud := newTestCloud(newFakeCLB(), newFakeCVM(instance))
+		cloud.vpc = fakeVpc
+		cloud.txConfig.NodeNameStrategy = testCase.config.NodeNameStrategy
+		cloud.txConfig.NodeAddressIPFamilyOrder = testCase.config.NodeAddressIPFamilyOrder
+		cloud.txConfig.NodeAddressSecondaryENI = testCase.config.NodeAddressSecondaryENI
+		cloud.txConfig.NodeInternalDNSSuffix = testCase.config.NodeInternalDNSSuffix
+
+		addresses := cloud.getNodeAddresses(context.Background(), instance, testCase.nodeName)
+		if !reflect.DeepEqual(addresses, testCase.expected) {
+			t.Errorf("%s: addresses = %v, want %v", testCase.name, addresses, testCase.expected)
+		}
+	}
+}
+
+func TestGetNodeAddressesENIError(t *testing.T) {
+	instance := newTestInstance("ins-1", "10.0.0.1")
+	fakeVpc := newFakeVPC()
+	fakeVpc.describeNetworkInterfacesErr = errors.New("internal error")
+	cloud := newTestCloud(newFakeCLB(), newFakeCVM(instance))
+	cloud.vpc = fakeVpc
+	cloud.txConfig.NodeAddressSecondaryENI = true
+
+	addresses := cloud.getNodeAddresses(context.Background(), instance, "worker-1")
+	expected := []v1.NodeAddress{
+		{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+		{Type: v1.NodeHostName, Address: "worker-1"},
+	}
+	if !reflect.DeepEqual(addresses, expected) {
+		t.Errorf("addresses = %v, want the primary addresses %v", addresses, expected)
+	}
+}
@@ -0,0 +1,203 @@
+package tencentcloud
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	cvm "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/cvm/v20170312"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog"
+)
+
+const (
+	defaultNodeLabelPrefix       = "cvm.tencentcloud.com/"
+	defaultNodeLabelSyncInterval = 60 * time.Second
+
+	// attributes of NodeLabelSyncConfig.Attributes
+	nodeLabelAttributeInstanceChargeType = "instance-charge-type"
+	nodeLabelAttributeInstanceType       = "instance-type"
+	nodeLabelAttributeInstanceFamily     = "instance-family"
+	nodeLabelAttributeProjectId          = "project-id"
+
+	// nodeLabelTagPrefix prefixes the label name of a CVM tag, e.g. cvm.tencentcloud.com/tag-pool
+	nodeLabelTagPrefix  = "tag-"
+	maxLabelValueLength = 63
+)
+
+var invalidLabelValueChars = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+
+// NodeLabelSyncConfig configures the sync of CVM tags and attributes to node labels
+type NodeLabelSyncConfig struct {
+	// Enabled starts the sync loop
+	Enabled bool `json:"enabled"`
+	// Interval is the interval between two syncs, in seconds
+	Interval int `json:"interval"`
+	// Prefix is the prefix of every synced label, a label key prefix ending with /, e.g. cvm.tencentcloud.com/.
+	// Labels with the prefix are owned by the sync and removed when stale
+	Prefix string `json:"prefix"`
+	// TagKeys is the allow-list of CVM tag keys synced as <prefix>tag-<key>
+	TagKeys []string `json:"tag_keys"`
+	// Attributes is the allow-list of instance attributes synced as <prefix><attribute>:
+	// instance-charge-type, instance-type, instance-family and project-id
+	Attributes []string `json:"attributes"`
+}
+
+// runNodeLabelSync syncs node labels every interval until stop is closed
+func (cloud *Cloud) runNodeLabelSync(interval time.Duration, stop <-chan struct{}) {
+	klog.V(3).Infof("tencentcloud.runNodeLabelSync: sync every %v\n", interval)
+	if !cloud.waitForNodeLister(stop) {
+		return
+	}
+	wait.Until(func() {
+		if err := cloud.syncNodeLabels(context.TODO()); err != nil {
+			klog.Warningf("tencentcloud.runNodeLabelSync: sync error: %v\n", err)
+		}
+	}, interval, stop)
+}
+
+// syncNodeLabels patches the labels of every node to the tags and attributes of its instance
+func (cloud *Cloud) syncNodeLabels(ctx context.Context) error {
+	klog.V(3).Infof("tencentcloud.syncNodeLabels(): entered\n")
+	nodes, err := cloud.listNodes(ctx)
+	if err != nil {
+		klog.Warningf("tencentcloud.syncNodeLabels: list nodes error: %v\n", err)
+		return err
+	}
+
+	prefix := cloud.txConfig.NodeLabelSync.Prefix
+	tagLabelNames := getNodeTagLabelNames(prefix, cloud.txConfig.NodeLabelSync.TagKeys)
+	for _, node := range nodes {
+		instance, err := cloud.getInstanceByNode(ctx, node)
+		if err != nil {
+			klog.Warningf("tencentcloud.syncNodeLabels: node %s Get error: %v\n", node.Name, err)
+			continue
+		}
+
+		desired := cloud.getNodeLabels(instance, tagLabelNames)
+		changes := make(map[string]interface{})
+		for key, value := range desired {
+			if node.Labels[key] != value {
+				changes[key] = value
+			}
+		}
+		for key := range node.Labels {
+			if _, ok := desired[key]; !ok && strings.HasPrefix(key, prefix) {
+				changes[key] = nil
+			}
+		}
+		if len(changes) == 0 {
+			continue
+		}
+
+		patch, err := json.Marshal(map[string]interface{}{"metadata": map[string]interface{}{"labels": changes}})
+		if err != nil {
+			return err
+		}
+		if _, err := cloud.kubeClient.CoreV1().Nodes().Patch(ctx, node.Name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+			klog.Warningf("tencentcloud.syncNodeLabels: node %s patch error: %v\n", node.Name, err)
+			continue
+		}
+		klog.V(3).Infof("tencentcloud.syncNodeLabels: node %s labels patched: %s\n", node.Name, string(patch))
+	}
+	return nil
+}
+
+// getNodeLabels returns the labels of the allowed tags and attributes of the instance,
+// tagLabelNames are the label names of the allowed tag keys
+func (cloud *Cloud) getNodeLabels(instance *cvm.Instance, tagLabelNames map[string]string) map[string]string {
+	config := cloud.txConfig.NodeLabelSync
+	labels := make(map[string]string)
+	set := func(name, value string) {
+		if value = sanitizeLabelValue(value); value != "" {
+			labels[config.Prefix+name] = value
+		}
+	}
+
+	for _, attribute := range config.Attributes {
+		switch attribute {
+		case nodeLabelAttributeInstanceChargeType:
+			if instance.InstanceChargeType != nil {
+				set(attribute, *instance.InstanceChargeType)
+			}
+		case nodeLabelAttributeInstanceType:
+			if instance.InstanceType != nil {
+				set(attribute, *instance.InstanceType)
+			}
+		case nodeLabelAttributeInstanceFamily:
+			// the family is the instance type before the size, e.g. S5 of S5.MEDIUM4
+			if instance.InstanceType != nil {
+				set(attribute, strings.SplitN(*instance.InstanceType, ".", 2)[0])
+			}
+		case nodeLabelAttributeProjectId:
+			if instance.Placement != nil && instance.Placement.ProjectId != nil {
+				set(attribute, strconv.FormatInt(*instance.Placement.ProjectId, 10))
+			}
+		}
+	}
+	for _, tag := range instance.Tags {
+		if tag.Key == nil || tag.Value == nil {
+			continue
+		}
+		if name, ok := tagLabelNames[*tag.Key]; ok {
+			set(name, *tag.Value)
+		}
+	}
+	return labels
+}
+
+// getNodeTagLabelNames returns the label names of the tag keys by key, <prefix>tag-<sanitized key>.
+// The keys whose label names collide or are not valid label names are skipped.
+func getNodeTagLabelNames(prefix string, tagKeys []string) map[string]string {
+	keysByName := make(map[string][]string)
+	for _, key := range tagKeys {
+		sanitized := sanitizeLabelValue(key)
+		if sanitized == "" {
+			klog.Warningf("tencentcloud.getNodeTagLabelNames: tag key %s skipped, no character is allowed in a label\n", key)
+			continue
+		}
+		name := sanitizeLabelValue(nodeLabelTagPrefix + sanitized)
+		if errs := validation.IsQualifiedName(prefix + name); len(errs) > 0 {
+			klog.Warningf("tencentcloud.getNodeTagLabelNames: tag key %s skipped, label %s%s is invalid: %s\n", key, prefix, name, strings.Join(errs, "; "))
+			continue
+		}
+		keysByName[name] = append(keysByName[name], key)
+	}
+
+	names := make(map[string]string)
+	for name, keys := range keysByName {
+		if len(keys) > 1 {
+			klog.Warningf("tencentcloud.getNodeTagLabelNames: tag keys %v skipped, they share the label %s%s\n", keys, prefix, name)
+			continue
+		}
+		names[keys[0]] = name
+	}
+	return names
+}
+
+// checkNodeLabelPrefix check the prefix of the synced labels is a label key prefix, i.e. <dns subdomain>/
+func checkNodeLabelPrefix(prefix string) error {
+	if !strings.HasSuffix(prefix, "/") {
+		return errors.New("prefix must end with /")
+	}
+	if errs := validation.IsDNS1123Subdomain(strings.TrimSuffix(prefix, "/")); len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// sanitizeLabelValue replaces the characters not allowed in a label value and truncates it to 63 characters
+func sanitizeLabelValue(value string) string {
+	value = invalidLabelValueChars.ReplaceAllString(value, "-")
+	if len(value) > maxLabelValueLength {
+		value = value[:maxLabelValueLength]
+	}
+	return strings.Trim(value, "-_.")
+}
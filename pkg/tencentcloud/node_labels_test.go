@@ -0,0 +1,101 @@
+package tencentcloud
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	cvm "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/cvm/v20170312"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestSyncNodeLabels(t *testing.T) {
+	instance := newTestInstance("ins-1", "10.0.0.1")
+	instance.Tags = []*cvm.Tag{
+		{Key: common.StringPtr("pool"), Value: common.StringPtr("gpu pool")},
+		{Key: common.StringPtr("owner"), Value: common.StringPtr("team-a")},
+	}
+	instance.Placement.ProjectId = common.Int64Ptr(1001)
+
+	cloud := newTestCloud(newFakeCLB(), newFakeCVM(instance))
+	cloud.txConfig.NodeLabelSync = NodeLabelSyncConfig{
+		Enabled:    true,
+		Prefix:     defaultNodeLabelPrefix,
+		TagKeys:    []string{"pool"},
+		Attributes: []string{nodeLabelAttributeInstanceChargeType, nodeLabelAttributeInstanceFamily, nodeLabelAttributeProjectId},
+	}
+	cloud.kubeClient = fake.NewSimpleClientset(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "10.0.0.1",
+			Labels: map[string]string{
+				"kubernetes.io/hostname":               "10.0.0.1",
+				"cvm.tencentcloud.com/tag-stale":       "true",
+				"cvm.tencentcloud.com/instance-family": "SA2",
+			},
+		},
+		Spec: v1.NodeSpec{ProviderID: "tencentcloud:///ap-guangzhou-3/ins-1"},
+	})
+
+	if err := cloud.syncNodeLabels(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	node, err := cloud.kubeClient.CoreV1().Nodes().Get(context.Background(), "10.0.0.1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]string{
+		"kubernetes.io/hostname":                    "10.0.0.1",
+		"cvm.tencentcloud.com/tag-pool":             "gpu-pool",
+		"cvm.tencentcloud.com/instance-charge-type": "POSTPAID_BY_HOUR",
+		"cvm.tencentcloud.com/instance-family":      "S5",
+		"cvm.tencentcloud.com/project-id":           "1001",
+	}
+	if !reflect.DeepEqual(node.Labels, expected) {
+		t.Errorf("labels = %v, want %v", node.Labels, expected)
+	}
+}
+
+func TestGetNodeTagLabelNames(t *testing.T) {
+	tagKeys := []string{"pool", "team/owner", "team owner", "env", "中文"}
+	expected := map[string]string{
+		"pool": "tag-pool",
+		"env":  "tag-env",
+	}
+	if names := getNodeTagLabelNames(defaultNodeLabelPrefix, tagKeys); !reflect.DeepEqual(names, expected) {
+		t.Errorf("getNodeTagLabelNames = %v, want %v", names, expected)
+	}
+}
+
+func TestCheckNodeLabelPrefix(t *testing.T) {
+	for _, prefix := range []string{defaultNodeLabelPrefix, "example.com/"} {
+		if err := checkNodeLabelPrefix(prefix); err != nil {
+			t.Errorf("%q: unexpected error: %v", prefix, err)
+		}
+	}
+	for _, prefix := range []string{"", "cvm.tencentcloud.com", "Example.com/", "a/b/"} {
+		if err := checkNodeLabelPrefix(prefix); err == nil {
+			t.Errorf("%q: expected an invalid prefix error", prefix)
+		}
+	}
+}
+
+func TestSanitizeLabelValue(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected string
+	}{
+		{value: "S5.MEDIUM4", expected: "S5.MEDIUM4"},
+		{value: "gpu pool/a", expected: "gpu-pool-a"},
+		{value: "-trimmed.", expected: "trimmed"},
+		{value: "中文", expected: ""},
+		{value: "a234567890123456789012345678901234567890123456789012345678901234567890", expected: "a23456789012345678901234567890123456789012345678901234567890123"},
+	}
+	for _, testCase := range testCases {
+		if actual := sanitizeLabelValue(testCase.value); actual != testCase.expected {
+			t.Errorf("sanitizeLabelValue(%q) = %q, want %q", testCase.value, actual, testCase.expected)
+		}
+	}
+}
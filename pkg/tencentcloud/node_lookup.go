@@ -0,0 +1,249 @@
+package tencentcloud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	cvm "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/cvm/v20170312"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	toolscache "k8s.io/client-go/tools/cache"
+	cloudProvider "k8s.io/cloud-provider"
+	"k8s.io/klog"
+)
+
+// node name strategies, i.e. what the kubernetes node name of a CVM is
+const (
+	// NodeNameStrategyPrivateIP names nodes after their primary private ip, e.g. --hostname-override=10.0.0.1
+	NodeNameStrategyPrivateIP = "private-ip"
+	// NodeNameStrategyInstanceID names nodes after their CVM instance id, e.g. ins-xxxxxxxx
+	NodeNameStrategyInstanceID = "instance-id"
+	// NodeNameStrategyInstanceName names nodes after their CVM instance name
+	NodeNameStrategyInstanceName = "instance-name"
+	// NodeNameStrategyHostname names nodes after a hostname resolving to their private ip
+	NodeNameStrategyHostname = "hostname"
+)
+
+var cacheNamePreVmName = "vm_name_" //cache key name pre for vm instance name
+
+// isValidNodeNameStrategy check the node name strategy is supported
+func isValidNodeNameStrategy(strategy string) bool {
+	switch strategy {
+	case NodeNameStrategyPrivateIP, NodeNameStrategyInstanceID, NodeNameStrategyInstanceName, NodeNameStrategyHostname:
+		return true
+	}
+	return false
+}
+
+// getInstanceByNodeName returns Tencent Cloud Instance for the node name, looked up by the node name strategy
+func (cloud *Cloud) getInstanceByNodeName(ctx context.Context, nodeName types.NodeName) (*cvm.Instance, error) {
+	name := string(nodeName)
+	switch cloud.txConfig.NodeNameStrategy {
+	case NodeNameStrategyInstanceID:
+		return cloud.getInstanceByInstanceID(ctx, name)
+	case NodeNameStrategyInstanceName:
+		return cloud.getInstanceByInstanceName(ctx, name)
+	case NodeNameStrategyHostname:
+		return cloud.getInstanceByHostname(ctx, name)
+	default:
+		return cloud.getInstanceByInstancePrivateIp(ctx, name)
+	}
+}
+
+// getInstanceByRegisteredNodeName returns Tencent Cloud Instance for the node name, looked up with getInstanceByNode
+// once the node is registered, by the node name strategy before
+func (cloud *Cloud) getInstanceByRegisteredNodeName(ctx context.Context, nodeName types.NodeName) (*cvm.Instance, error) {
+	node, err := cloud.getNode(ctx, nodeName)
+	if err == nil {
+		return cloud.getInstanceByNode(ctx, node)
+	}
+	if !apierrors.IsNotFound(err) {
+		klog.Warningf("tencentcloud.getInstanceByRegisteredNodeName: get node %s error: %v\n", nodeName, err)
+	}
+	return cloud.getInstanceByNodeName(ctx, nodeName)
+}
+
+// getNode returns the node named nodeName from the node informer once it is synced, from the API server before,
+// a NotFound error without a kubernetes client. The node of the informer is shared, copy it before any change.
+func (cloud *Cloud) getNode(ctx context.Context, nodeName types.NodeName) (*v1.Node, error) {
+	if cloud.nodeLister != nil && cloud.nodeListerSynced() {
+		return cloud.nodeLister.Get(string(nodeName))
+	}
+	if cloud.kubeClient == nil {
+		return nil, apierrors.NewNotFound(v1.Resource("nodes"), string(nodeName))
+	}
+	return cloud.kubeClient.CoreV1().Nodes().Get(ctx, string(nodeName), metav1.GetOptions{})
+}
+
+// getInstanceByNode returns Tencent Cloud Instance for node, looked up by node.Spec.ProviderID first,
+// then by the first ipv4 InternalIP of node.Status.Addresses, then by the node name strategy.
+// Instances can not be looked up by ipv6 address, the ipv6 InternalIPs are skipped.
+func (cloud *Cloud) getInstanceByNode(ctx context.Context, node *v1.Node) (*cvm.Instance, error) {
+	if node.Spec.ProviderID != "" {
+		return cloud.getInstanceByProviderID(ctx, node.Spec.ProviderID)
+	}
+	for _, address := range node.Status.Addresses {
+		if ip := net.ParseIP(address.Address); address.Type == v1.NodeInternalIP && ip != nil && ip.To4() != nil {
+			return cloud.getInstanceByInstancePrivateIp(ctx, address.Address)
+		}
+	}
+	return cloud.getInstanceByNodeName(ctx, types.NodeName(node.Name))
+}
+
+// listNodes returns the nodes from the node informer once Initialize started it, from the API server before,
+// none without a kubernetes client. The nodes of the informer are shared, copy them before any change.
+func (cloud *Cloud) listNodes(ctx context.Context) ([]*v1.Node, error) {
+	if cloud.nodeLister != nil {
+		if !cloud.nodeListerSynced() {
+			return nil, errors.New("node informer not synced")
+		}
+		return cloud.nodeLister.List(labels.Everything())
+	}
+	if cloud.kubeClient == nil {
+		return nil, nil
+	}
+
+	nodes, err := cloud.kubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*v1.Node, 0, len(nodes.Items))
+	for i := range nodes.Items {
+		ret = append(ret, &nodes.Items[i])
+	}
+	return ret, nil
+}
+
+// waitForNodeLister waits for the node informer to sync, false when stop is closed first
+func (cloud *Cloud) waitForNodeLister(stop <-chan struct{}) bool {
+	if cloud.nodeListerSynced == nil {
+		return true
+	}
+	return toolscache.WaitForCacheSync(stop, cloud.nodeListerSynced)
+}
+
+// getInstancesByNodeNames returns Tencent Cloud Instances for multi node names, unknown nodes are skipped
+func (cloud *Cloud) getInstancesByNodeNames(ctx context.Context, nodeNames []string) ([]*cvm.Instance, error) {
+	if cloud.txConfig.NodeNameStrategy == "" || cloud.txConfig.NodeNameStrategy == NodeNameStrategyPrivateIP {
+		return cloud.getInstanceByInstancePrivateIps(ctx, nodeNames)
+	}
+
+	instances := make([]*cvm.Instance, 0)
+	for _, nodeName := range nodeNames {
+		instance, err := cloud.getInstanceByNodeName(ctx, types.NodeName(nodeName))
+		if err == cloudProvider.InstanceNotFound {
+			klog.Warningf("tencentcloud.getInstancesByNodeNames: instance of node %s not found\n", nodeName)
+			continue
+		}
+		if err != nil {
+			klog.Warningf("tencentcloud.getInstancesByNodeNames: Get error: %v, node: %s\n", err, nodeName)
+			return nil, err
+		}
+		instances = append(instances, instance)
+	}
+	return instances, nil
+}
+
+// getInstanceByInstanceName returns Tencent Cloud Instance for instance name, the name must be unique in the vpc
+func (cloud *Cloud) getInstanceByInstanceName(ctx context.Context, instanceName string) (*cvm.Instance, error) {
+	klog.V(3).Infof("tencentcloud.getInstanceByInstanceName(\"%s\"): entered\n", instanceName)
+
+	cacheKey := cacheNamePreVmName + instanceName
+	cacheValue, exist := cloud.instanceCache.Get(cacheKey)
+	if exist {
+		if cacheValue == nil {
+			klog.V(3).Infof("tencentcloud.getInstanceByInstanceName: negative cache return(name:%s): nil, %v\n", instanceName, cloudProvider.InstanceNotFound)
+			return nil, cloudProvider.InstanceNotFound
+		}
+		klog.V(3).Infof("tencentcloud.getInstanceByInstanceName: cache return(name:%s):  %T, nil\n", instanceName, cacheValue)
+		return cacheValue, nil
+	}
+
+	value, err, _ := cloud.instanceLookups.Do(cacheKey, func() (interface{}, error) {
+		return cloud.describeInstanceByInstanceName(ctx, instanceName)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*cvm.Instance), nil
+}
+
+// describeInstanceByInstanceName calls DescribeInstances for instanceName and caches the result, InstanceNotFound included
+func (cloud *Cloud) describeInstanceByInstanceName(ctx context.Context, instanceName string) (*cvm.Instance, error) {
+	cacheKey := cacheNamePreVmName + instanceName
+	request := cvm.NewDescribeInstancesRequest()
+	request.Filters = []*cvm.Filter{
+		{
+			Values: common.StringPtrs([]string{instanceName}),
+			Name:   common.StringPtr("instance-name"),
+		},
+		{
+			Values: common.StringPtrs([]string{cloud.txConfig.VpcId}),
+			Name:   common.StringPtr("vpc-id"),
+		},
+	}
+	request.Limit = common.Int64Ptr(describeInstancesMaxLimit)
+
+	request.SetContext(ctx)
+	response, err := cloud.cvm.DescribeInstances(request)
+	if err != nil {
+		klog.Warningf("tencentcloud.getInstanceByInstanceName: tencentcloud API error: %v\n", err)
+		klog.V(3).Infof("tencentcloud.getInstanceByInstanceName: return: nil, %v\n", err)
+		return nil, err
+	}
+	// the instance-name filter is a fuzzy match
+	var found *cvm.Instance
+	for _, instance := range response.Response.InstanceSet {
+		if *instance.VirtualPrivateCloud.VpcId != cloud.txConfig.VpcId || *instance.InstanceName != instanceName {
+			continue
+		}
+		if found != nil {
+			err := fmt.Errorf("instance name %s is shared by %s and %s", instanceName, *found.InstanceId, *instance.InstanceId)
+			klog.Warningf("tencentcloud.getInstanceByInstanceName: Get error: %v\n", err)
+			return nil, err
+		}
+		found = instance
+	}
+	if found == nil {
+		cloud.instanceCache.SetWithTTL(cacheKey, nil, NegativeTTLTime)
+		klog.V(3).Infof("tencentcloud.getInstanceByInstanceName: return:  nil, %v\n", cloudProvider.InstanceNotFound)
+		return nil, cloudProvider.InstanceNotFound
+	}
+
+	cloud.instanceCache.Set(cacheKey, found)
+	klog.V(3).Infof("tencentcloud.getInstanceByInstanceName: return(name:%s):  %T, nil\n", instanceName, *found)
+	return found, nil
+}
+
+// getInstanceByHostname returns Tencent Cloud Instance for a hostname resolving to its private ip
+func (cloud *Cloud) getInstanceByHostname(ctx context.Context, hostname string) (*cvm.Instance, error) {
+	klog.V(3).Infof("tencentcloud.getInstanceByHostname(\"%s\"): entered\n", hostname)
+	lookupHost := cloud.lookupHost
+	if lookupHost == nil {
+		lookupHost = net.DefaultResolver.LookupHost
+	}
+
+	addresses, err := lookupHost(ctx, hostname)
+	if err != nil {
+		klog.Warningf("tencentcloud.getInstanceByHostname: Get error: %v\n", err)
+		klog.V(3).Infof("tencentcloud.getInstanceByHostname: return: nil, %v\n", err)
+		return nil, err
+	}
+	for _, address := range addresses {
+		instance, err := cloud.getInstanceByInstancePrivateIp(ctx, address)
+		if err == cloudProvider.InstanceNotFound {
+			continue
+		}
+		klog.V(3).Infof("tencentcloud.getInstanceByHostname: return(address:%s): %v\n", address, err)
+		return instance, err
+	}
+
+	klog.V(3).Infof("tencentcloud.getInstanceByHostname: return:  nil, %v\n", cloudProvider.InstanceNotFound)
+	return nil, cloudProvider.InstanceNotFound
+}
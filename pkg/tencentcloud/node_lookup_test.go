@@ -0,0 +1,213 @@
+package tencentcloud
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	toolscache "k8s.io/client-go/tools/cache"
+	cloudProvider "k8s.io/cloud-provider"
+)
+
+func TestGetInstanceByNodeName(t *testing.T) {
+	named := newTestInstance("ins-2", "10.0.0.2")
+	named.InstanceName = common.StringPtr("node-2.example.com")
+	fuzzy := newTestInstance("ins-3", "10.0.0.3")
+	fuzzy.InstanceName = common.StringPtr("node-2.example.com.bak")
+
+	testCases := []struct {
+		strategy string
+		nodeName string
+		expected string
+	}{
+		{strategy: "", nodeName: "10.0.0.1", expected: "ins-1"},
+		{strategy: NodeNameStrategyPrivateIP, nodeName: "10.0.0.2", expected: "ins-2"},
+		{strategy: NodeNameStrategyInstanceID, nodeName: "ins-2", expected: "ins-2"},
+		{strategy: NodeNameStrategyInstanceName, nodeName: "node-2.example.com", expected: "ins-2"},
+		{strategy: NodeNameStrategyHostname, nodeName: "node-1.example.com", expected: "ins-1"},
+		{strategy: NodeNameStrategyInstanceName, nodeName: "node-9.example.com"},
+		{strategy: NodeNameStrategyHostname, nodeName: "unknown.example.com"},
+	}
+	for _, testCase := range testCases {
+		cloud := newTestCloud(newFakeCLB(), newFakeCVM(newTestInstance("ins-1", "10.0.0.1"), named, fuzzy))
+		cloud.txConfig.NodeNameStrategy = testCase.strategy
+		cloud.lookupHost = func(ctx context.Context, host string) ([]string, error) {
+			if host == "node-1.example.com" {
+				return []string{"fd00::1", "10.0.0.1"}, nil
+			}
+			return []string{"10.9.9.9"}, nil
+		}
+
+		instance, err := cloud.getInstanceByNodeName(context.Background(), types.NodeName(testCase.nodeName))
+		if testCase.expected == "" {
+			if err != cloudProvider.InstanceNotFound {
+				t.Errorf("%s %s: expected InstanceNotFound, got %v", testCase.strategy, testCase.nodeName, err)
+			}
+			continue
+		}
+		if err != nil || *instance.InstanceId != testCase.expected {
+			t.Errorf("%s %s: got %v, %v, want %s", testCase.strategy, testCase.nodeName, instance, err, testCase.expected)
+		}
+	}
+}
+
+func TestGetInstanceByInstanceNameAmbiguous(t *testing.T) {
+	first := newTestInstance("ins-1", "10.0.0.1")
+	first.InstanceName = common.StringPtr("node")
+	second := newTestInstance("ins-2", "10.0.0.2")
+	second.InstanceName = common.StringPtr("node")
+	cloud := newTestCloud(newFakeCLB(), newFakeCVM(first, second))
+
+	if _, err := cloud.getInstanceByInstanceName(context.Background(), "node"); err == nil || err == cloudProvider.InstanceNotFound {
+		t.Errorf("expected an ambiguous instance name error, got %v", err)
+	}
+}
+
+func TestGetInstanceByNode(t *testing.T) {
+	cloud := newTestCloud(newFakeCLB(), newFakeCVM(newTestInstance("ins-1", "10.0.0.1"), newTestInstance("ins-2", "10.0.0.2")))
+
+	testCases := []struct {
+		node     *v1.Node
+		expected string
+	}{
+		{
+			node: &v1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+				Spec:       v1.NodeSpec{ProviderID: "tencentcloud:///ap-guangzhou-3/ins-1"},
+			},
+			expected: "ins-1",
+		},
+		{
+			node: &v1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node-2"},
+				Status: v1.NodeStatus{Addresses: []v1.NodeAddress{
+					{Type: v1.NodeHostName, Address: "node-2"},
+					{Type: v1.NodeInternalIP, Address: "10.0.0.2"},
+				}},
+			},
+			expected: "ins-2",
+		},
+		{node: &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "10.0.0.1"}}, expected: "ins-1"},
+		{
+			// the ipv6 address order puts the ipv6 InternalIP first
+			node: &v1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node-4"},
+				Status: v1.NodeStatus{Addresses: []v1.NodeAddress{
+					{Type: v1.NodeInternalIP, Address: "2402:4e00:1::1"},
+					{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+				}},
+			},
+			expected: "ins-1",
+		},
+		{
+			node: &v1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node-3"},
+				Spec:       v1.NodeSpec{ProviderID: "tencentcloud:///ap-guangzhou-3/ins-3"},
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		instance, err := cloud.getInstanceByNode(context.Background(), testCase.node)
+		if testCase.expected == "" {
+			if err != cloudProvider.InstanceNotFound {
+				t.Errorf("%s: expected InstanceNotFound, got %v, %v", testCase.node.Name, instance, err)
+			}
+			continue
+		}
+		if err != nil || *instance.InstanceId != testCase.expected {
+			t.Errorf("%s: got %v, %v, want %s", testCase.node.Name, instance, err, testCase.expected)
+		}
+	}
+}
+
+func TestInstancesByRegisteredNode(t *testing.T) {
+	cloud := newTestCloud(newFakeCLB(), newFakeCVM(newTestInstance("ins-1", "10.0.0.1")))
+	// node-1 is not a private ip, only its provider id finds its instance
+	cloud.kubeClient = fake.NewSimpleClientset(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Spec:       v1.NodeSpec{ProviderID: "tencentcloud:///ap-guangzhou-3/ins-1"},
+	})
+
+	instanceID, err := cloud.InstanceID(context.Background(), "node-1")
+	if err != nil || instanceID != "/ap-guangzhou-3/ins-1" {
+		t.Errorf("InstanceID = %q, %v, want /ap-guangzhou-3/ins-1", instanceID, err)
+	}
+	instanceType, err := cloud.InstanceType(context.Background(), "node-1")
+	if err != nil || instanceType != "S5.MEDIUM4" {
+		t.Errorf("InstanceType = %q, %v, want S5.MEDIUM4", instanceType, err)
+	}
+	zone, err := cloud.GetZoneByNodeName(context.Background(), "node-1")
+	if err != nil || zone.FailureDomain != "ap-guangzhou-3" {
+		t.Errorf("GetZoneByNodeName = %+v, %v, want ap-guangzhou-3", zone, err)
+	}
+
+	// a node not registered yet is looked up by the node name strategy
+	instanceID, err = cloud.InstanceID(context.Background(), "10.0.0.1")
+	if err != nil || instanceID != "/ap-guangzhou-3/ins-1" {
+		t.Errorf("InstanceID of an unregistered node = %q, %v, want /ap-guangzhou-3/ins-1", instanceID, err)
+	}
+	if _, err := cloud.InstanceID(context.Background(), "node-2"); err != cloudProvider.InstanceNotFound {
+		t.Errorf("expected InstanceNotFound for an unknown node, got %v", err)
+	}
+}
+
+func TestGetNodeFromLister(t *testing.T) {
+	cloud := newTestCloud(newFakeCLB(), newFakeCVM(newTestInstance("ins-1", "10.0.0.1")))
+	kubeClient := fake.NewSimpleClientset(&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}})
+	cloud.kubeClient = kubeClient
+	indexer := toolscache.NewIndexer(toolscache.MetaNamespaceKeyFunc, toolscache.Indexers{})
+	indexer.Add(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Spec:       v1.NodeSpec{ProviderID: "tencentcloud:///ap-guangzhou-3/ins-1"},
+	})
+	cloud.nodeLister = corelisters.NewNodeLister(indexer)
+	synced := false
+	cloud.nodeListerSynced = func() bool { return synced }
+
+	// the API server is asked until the informer has synced
+	if _, err := cloud.getNode(context.Background(), "node-1"); err != nil {
+		t.Fatalf("getNode error: %v", err)
+	}
+	if gets := len(kubeClient.Actions()); gets != 1 {
+		t.Errorf("getNode made %d kubernetes calls before the sync, want 1", gets)
+	}
+
+	synced = true
+	kubeClient.ClearActions()
+	instanceID, err := cloud.InstanceID(context.Background(), "node-1")
+	if err != nil || instanceID != "/ap-guangzhou-3/ins-1" {
+		t.Errorf("InstanceID = %q, %v, want /ap-guangzhou-3/ins-1", instanceID, err)
+	}
+	if _, err := cloud.getNode(context.Background(), "node-2"); !apierrors.IsNotFound(err) {
+		t.Errorf("getNode of an unknown node = %v, want NotFound", err)
+	}
+	if gets := len(kubeClient.Actions()); gets != 0 {
+		t.Errorf("made %d kubernetes calls after the sync, want none", gets)
+	}
+}
+
+func TestCheckConfigNodeNameStrategy(t *testing.T) {
+	config := TxCloudConfig{
+		Region:            "ap-guangzhou",
+		VpcId:             "vpc-test",
+		CLBNamePrefix:     "test",
+		TagKey:            "cluster",
+		SecretId:          "id",
+		SecretKey:         "key",
+		ClusterRouteTable: "route-table",
+		NodeNameStrategy:  NodeNameStrategyInstanceName,
+	}
+	if err := checkConfig(config); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	config.NodeNameStrategy = "dns"
+	if err := checkConfig(config); err == nil {
+		t.Errorf("expected unsupported node name strategy to be rejected")
+	}
+}
@@ -0,0 +1,46 @@
+package tencentcloud
+
+import (
+	"context"
+	"encoding/json"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+)
+
+// patchNode patches the spec of node to the spec of updated with a strategic merge patch,
+// the taints and fields changed by others since node was read are kept
+func (cloud *Cloud) patchNode(ctx context.Context, node, updated *v1.Node) error {
+	patch, err := createNodePatch(node, updated)
+	if err != nil {
+		return err
+	}
+	_, err = cloud.kubeClient.CoreV1().Nodes().Patch(ctx, node.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+// patchNodeStatus patches the status of node to the status of updated with a strategic merge patch,
+// the conditions merge by type so the conditions of the kubelet and other controllers are kept
+func (cloud *Cloud) patchNodeStatus(ctx context.Context, node, updated *v1.Node) error {
+	patch, err := createNodePatch(node, updated)
+	if err != nil {
+		return err
+	}
+	_, err = cloud.kubeClient.CoreV1().Nodes().PatchStatus(ctx, node.Name, patch)
+	return err
+}
+
+// createNodePatch returns the two-way strategic merge patch from node to updated
+func createNodePatch(node, updated *v1.Node) ([]byte, error) {
+	oldData, err := json.Marshal(node)
+	if err != nil {
+		return nil, err
+	}
+	newData, err := json.Marshal(updated)
+	if err != nil {
+		return nil, err
+	}
+	return strategicpatch.CreateTwoWayMergePatch(oldData, newData, v1.Node{})
+}
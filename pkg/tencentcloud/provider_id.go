@@ -0,0 +1,108 @@
+package tencentcloud
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"k8s.io/klog"
+)
+
+// legacyProviderName is the provider name of the qcloud cloud controller manager
+const legacyProviderName = "qcloud"
+
+var instanceIDPattern = regexp.MustCompile(`^ins-[0-9a-z]+$`)
+
+// ProviderID is a parsed node providerID, formatted as tencentcloud:///<zone>/<instanceId>
+type ProviderID struct {
+	// Zone is the availability zone, empty when the providerID has none, e.g. a bare instance id
+	Zone string
+	// InstanceID is the CVM instance id, e.g. ins-xxxxxxxx
+	InstanceID string
+	// Legacy is true when the providerID is not in the canonical format and should be migrated
+	Legacy bool
+}
+
+// ProviderIDError is returned for a providerID that can not be parsed
+type ProviderIDError struct {
+	ProviderID string
+	Reason     string
+}
+
+func (e *ProviderIDError) Error() string {
+	return fmt.Sprintf("invalid providerID %q: %s", e.ProviderID, e.Reason)
+}
+
+// ParseProviderID parses the providerID formats:
+//
+//	tencentcloud:///<zone>/<instanceId> (canonical)
+//	tencentcloud:///<instanceId>
+//	qcloud:///<zone>/<instanceId>
+//	/<zone>/<instanceId>, as returned by InstanceID
+//	<instanceId>
+func ParseProviderID(providerID string) (ProviderID, error) {
+	path, legacy := providerID, true
+	if i := strings.Index(providerID, "://"); i >= 0 {
+		switch scheme := providerID[:i]; scheme {
+		case providerName:
+			legacy = false
+		case legacyProviderName:
+		default:
+			return ProviderID{}, &ProviderIDError{ProviderID: providerID, Reason: fmt.Sprintf("unsupported scheme %q", scheme)}
+		}
+		path = providerID[i+len("://"):]
+		if !strings.HasPrefix(path, "/") {
+			return ProviderID{}, &ProviderIDError{ProviderID: providerID, Reason: "host must be empty"}
+		}
+	}
+
+	var parsed ProviderID
+	parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	switch len(parts) {
+	case 1:
+		parsed = ProviderID{InstanceID: parts[0], Legacy: true}
+	case 2:
+		if parts[0] == "" {
+			return ProviderID{}, &ProviderIDError{ProviderID: providerID, Reason: "zone is empty"}
+		}
+		parsed = ProviderID{Zone: parts[0], InstanceID: parts[1], Legacy: legacy}
+	default:
+		return ProviderID{}, &ProviderIDError{ProviderID: providerID, Reason: "expected [<zone>/]<instanceId>"}
+	}
+	if !instanceIDPattern.MatchString(parsed.InstanceID) {
+		return ProviderID{}, &ProviderIDError{ProviderID: providerID, Reason: fmt.Sprintf("%q is not an instance id", parsed.InstanceID)}
+	}
+	return parsed, nil
+}
+
+// String returns the providerID in the canonical format, zone-less when the zone is unknown
+func (p ProviderID) String() string {
+	if p.Zone == "" {
+		return fmt.Sprintf("%s:///%s", providerName, p.InstanceID)
+	}
+	return fmt.Sprintf("%s:///%s/%s", providerName, p.Zone, p.InstanceID)
+}
+
+// MigrateProviderID returns providerID in the canonical format tencentcloud:///<zone>/<instanceId>,
+// the zone of a zone-less providerID is looked up
+func (cloud *Cloud) MigrateProviderID(ctx context.Context, providerID string) (string, error) {
+	parsed, err := ParseProviderID(providerID)
+	if err != nil {
+		return "", err
+	}
+	if !parsed.Legacy {
+		return providerID, nil
+	}
+	if parsed.Zone == "" {
+		instance, err := cloud.getInstanceByInstanceID(ctx, parsed.InstanceID)
+		if err != nil {
+			return "", err
+		}
+		parsed.Zone = *instance.Placement.Zone
+	}
+
+	migrated := parsed.String()
+	klog.V(3).Infof("tencentcloud.MigrateProviderID: migrate %s to %s\n", providerID, migrated)
+	return migrated, nil
+}
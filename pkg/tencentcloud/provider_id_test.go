@@ -0,0 +1,128 @@
+package tencentcloud
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestParseProviderID(t *testing.T) {
+	testCases := []struct {
+		providerID string
+		expected   ProviderID
+		canonical  string
+	}{
+		{
+			providerID: "tencentcloud:///ap-guangzhou-3/ins-1a2b3c4d",
+			expected:   ProviderID{Zone: "ap-guangzhou-3", InstanceID: "ins-1a2b3c4d"},
+			canonical:  "tencentcloud:///ap-guangzhou-3/ins-1a2b3c4d",
+		},
+		{
+			providerID: "qcloud:///ap-guangzhou-3/ins-1a2b3c4d",
+			expected:   ProviderID{Zone: "ap-guangzhou-3", InstanceID: "ins-1a2b3c4d", Legacy: true},
+			canonical:  "tencentcloud:///ap-guangzhou-3/ins-1a2b3c4d",
+		},
+		{
+			providerID: "/ap-guangzhou-3/ins-1a2b3c4d",
+			expected:   ProviderID{Zone: "ap-guangzhou-3", InstanceID: "ins-1a2b3c4d", Legacy: true},
+			canonical:  "tencentcloud:///ap-guangzhou-3/ins-1a2b3c4d",
+		},
+		{
+			providerID: "tencentcloud:///ins-1a2b3c4d",
+			expected:   ProviderID{InstanceID: "ins-1a2b3c4d", Legacy: true},
+			canonical:  "tencentcloud:///ins-1a2b3c4d",
+		},
+		{
+			providerID: "ins-1a2b3c4d",
+			expected:   ProviderID{InstanceID: "ins-1a2b3c4d", Legacy: true},
+			canonical:  "tencentcloud:///ins-1a2b3c4d",
+		},
+	}
+	for _, testCase := range testCases {
+		parsed, err := ParseProviderID(testCase.providerID)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", testCase.providerID, err)
+			continue
+		}
+		if parsed != testCase.expected {
+			t.Errorf("%s: parsed %+v, want %+v", testCase.providerID, parsed, testCase.expected)
+		}
+		if parsed.String() != testCase.canonical {
+			t.Errorf("%s: formatted %s, want %s", testCase.providerID, parsed.String(), testCase.canonical)
+		}
+
+		// the canonical format round-trips
+		reparsed, err := ParseProviderID(parsed.String())
+		if err != nil || reparsed.String() != testCase.canonical || reparsed.InstanceID != testCase.expected.InstanceID {
+			t.Errorf("%s: round trip gave %+v, %v", testCase.providerID, reparsed, err)
+		}
+	}
+}
+
+func TestParseProviderIDErrors(t *testing.T) {
+	for _, providerID := range []string{
+		"",
+		"aws:///us-east-1a/i-0123456789",
+		"tencentcloud://host/ap-guangzhou-3/ins-1a2b3c4d",
+		"tencentcloud:///ap-guangzhou-3/ins-1a2b3c4d/extra",
+		"tencentcloud:////ins-1a2b3c4d",
+		"tencentcloud:///ap-guangzhou-3/lb-1a2b3c4d",
+		"10.0.0.1",
+	} {
+		_, err := ParseProviderID(providerID)
+		var providerIDError *ProviderIDError
+		if !errors.As(err, &providerIDError) {
+			t.Errorf("%q: expected a ProviderIDError, got %v", providerID, err)
+		}
+	}
+}
+
+func TestGetInstanceByLegacyProviderID(t *testing.T) {
+	cloud := newTestCloud(newFakeCLB(), newFakeCVM(newTestInstance("ins-1", "10.0.0.1")))
+	for _, providerID := range []string{
+		"tencentcloud:///ap-guangzhou-3/ins-1",
+		"tencentcloud:///ins-1",
+		"qcloud:///ap-guangzhou-3/ins-1",
+		"/ap-guangzhou-3/ins-1",
+		"ins-1",
+	} {
+		instance, err := cloud.getInstanceByProviderID(context.Background(), providerID)
+		if err != nil || *instance.InstanceId != "ins-1" {
+			t.Errorf("%s: expected ins-1, got %v, %v", providerID, instance, err)
+		}
+	}
+}
+
+func TestMigrateProviderID(t *testing.T) {
+	cloud := newTestCloud(newFakeCLB(), newFakeCVM(newTestInstance("ins-1", "10.0.0.1")))
+	for _, providerID := range []string{
+		"tencentcloud:///ap-guangzhou-3/ins-1",
+		"tencentcloud:///ins-1",
+		"qcloud:///ap-guangzhou-3/ins-1",
+		"/ap-guangzhou-3/ins-1",
+		"ins-1",
+	} {
+		migrated, err := cloud.MigrateProviderID(context.Background(), providerID)
+		if err != nil || migrated != "tencentcloud:///ap-guangzhou-3/ins-1" {
+			t.Errorf("%s: migrated to %s, %v", providerID, migrated, err)
+			continue
+		}
+
+		// the migrated providerID round-trips and is migrated already
+		parsed, err := ParseProviderID(migrated)
+		if err != nil || parsed.Legacy || parsed.String() != migrated {
+			t.Errorf("%s: migrated %s parsed to %+v, %v", providerID, migrated, parsed, err)
+		}
+		if again, err := cloud.MigrateProviderID(context.Background(), migrated); err != nil || again != migrated {
+			t.Errorf("%s: migrated %s again to %s, %v", providerID, migrated, again, err)
+		}
+	}
+
+	if _, err := cloud.MigrateProviderID(context.Background(), "ins-2"); err == nil {
+		t.Errorf("expected an error migrating the providerID of an unknown instance")
+	}
+	var providerIDError *ProviderIDError
+	if _, err := cloud.MigrateProviderID(context.Background(), "aws:///us-east-1a/i-0123456789"); !errors.As(err, &providerIDError) {
+		t.Errorf("expected a ProviderIDError, got %v", err)
+	}
+}
@@ -0,0 +1,235 @@
+package tencentcloud
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	vpc "github.com/weimob-tech/cloud-provider-tencent/pkg/vpc/v20170312"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog"
+)
+
+const (
+	// RouteConflictEventReason is the reason of the node events of a route CIDR conflict
+	RouteConflictEventReason = "RouteCIDRConflict"
+
+	// assistantCidrTypeContainer is the type of the vpc assistant CIDRs reserved for pods
+	assistantCidrTypeContainer = 1
+	describeSubnetsMaxLimit    = 100
+
+	cacheNamePreVpcCIDR    = "vpc_cidr_"    // cache key name pre for the CIDRs of a vpc
+	cacheNamePreRouteTable = "route_table_" // cache key name pre for the routes of a route table
+)
+
+// RouteCIDRConflictError is returned for a route CIDR overlapping the vpc, one of its subnets or another route
+type RouteCIDRConflictError struct {
+	// CIDR is the conflicting route CIDR
+	CIDR string
+	// Conflict describes what CIDR overlaps, e.g. subnet subnet-xxxxxxxx 10.0.1.0/24
+	Conflict string
+}
+
+func (e *RouteCIDRConflictError) Error() string {
+	return fmt.Sprintf("route cidr %s overlaps %s", e.CIDR, e.Conflict)
+}
+
+// vpcCIDR is a CIDR of the vpc and what it belongs to
+type vpcCIDR struct {
+	owner string
+	cidr  *net.IPNet
+}
+
+// cidrsOverlap check two CIDRs share an address, i.e. one contains the other
+func cidrsOverlap(a, b *net.IPNet) bool {
+	return a.Contains(b.IP) || b.Contains(a.IP)
+}
+
+// cidrContains check b is equal to or more specific than a
+func cidrContains(a, b *net.IPNet) bool {
+	aOnes, aBits := a.Mask.Size()
+	bOnes, bBits := b.Mask.Size()
+	return aBits == bBits && aOnes <= bOnes && a.Contains(b.IP)
+}
+
+// listVpcCIDRs returns the CIDRs of the subnets of the vpc, then the CIDRs of the vpc and its assistant CIDRs
+// but the container ones, the most specific first
+func (cloud *Cloud) listVpcCIDRs(ctx context.Context) ([]vpcCIDR, error) {
+	cacheKey := cacheNamePreVpcCIDR + cloud.txConfig.VpcId
+	if cidrs, ok := cloud.routeCIDRCache.Get(cacheKey); ok {
+		return cidrs, nil
+	}
+	cidrs, err := cloud.describeVpcCIDRs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cloud.routeCIDRCache.Set(cacheKey, cidrs)
+	return cidrs, nil
+}
+
+// describeVpcCIDRs describes the vpc and its subnets for listVpcCIDRs
+func (cloud *Cloud) describeVpcCIDRs(ctx context.Context) ([]vpcCIDR, error) {
+	request := vpc.NewDescribeVpcsRequest()
+	request.VpcIds = common.StringPtrs([]string{cloud.txConfig.VpcId})
+	request.SetContext(ctx)
+	response, err := cloud.vpc.DescribeVpcs(request)
+	if err != nil {
+		klog.Warningf("tencentcloud.describeVpcCIDRs: tencentcloud API error: %v\n", err)
+		return nil, err
+	}
+	if len(response.Response.VpcSet) == 0 {
+		return nil, fmt.Errorf("vpc %s not found", cloud.txConfig.VpcId)
+	}
+
+	var cidrs, subnetCIDRs []vpcCIDR
+	add := func(into *[]vpcCIDR, owner string, cidr *string) {
+		if cidr == nil || *cidr == "" {
+			return
+		}
+		_, ipNet, err := net.ParseCIDR(*cidr)
+		if err != nil {
+			klog.Warningf("tencentcloud.describeVpcCIDRs: %s cidr %s parse error: %v\n", owner, *cidr, err)
+			return
+		}
+		*into = append(*into, vpcCIDR{owner: owner, cidr: ipNet})
+	}
+	for _, v := range response.Response.VpcSet {
+		add(&cidrs, "vpc "+*v.VpcId, v.CidrBlock)
+		add(&cidrs, "vpc "+*v.VpcId, v.Ipv6CidrBlock)
+		for _, assistant := range v.AssistantCidrSet {
+			if assistant.AssistantType != nil && *assistant.AssistantType == assistantCidrTypeContainer {
+				continue
+			}
+			add(&cidrs, "vpc "+*v.VpcId+" assistant cidr", assistant.CidrBlock)
+		}
+	}
+
+	var offset int
+	for {
+		request := vpc.NewDescribeSubnetsRequest()
+		request.Filters = []*vpc.Filter{{Name: common.StringPtr("vpc-id"), Values: common.StringPtrs([]string{cloud.txConfig.VpcId})}}
+		request.Offset = common.StringPtr(strconv.Itoa(offset))
+		request.Limit = common.StringPtr(strconv.Itoa(describeSubnetsMaxLimit))
+		request.SetContext(ctx)
+		response, err := cloud.vpc.DescribeSubnets(request)
+		if err != nil {
+			klog.Warningf("tencentcloud.describeVpcCIDRs: tencentcloud API error: %v\n", err)
+			return nil, err
+		}
+		for _, subnet := range response.Response.SubnetSet {
+			add(&subnetCIDRs, "subnet "+*subnet.SubnetId, subnet.CidrBlock)
+			add(&subnetCIDRs, "subnet "+*subnet.SubnetId, subnet.Ipv6CidrBlock)
+		}
+		offset += len(response.Response.SubnetSet)
+		if len(response.Response.SubnetSet) == 0 || response.Response.TotalCount == nil || uint64(offset) >= *response.Response.TotalCount {
+			return append(subnetCIDRs, cidrs...), nil
+		}
+	}
+}
+
+// checkRouteConflict check the CIDR of route overlaps neither the vpc nor its subnets, and contains no other route
+// of the table, the same destination via another gateway included. Broader routes, e.g. a 0.0.0.0/0 NAT route, are
+// less specific than route and do not conflict. Returns true when the table has the route already.
+func (cloud *Cloud) checkRouteConflict(ctx context.Context, backend routeBackend, route *cloudRoute) (bool, error) {
+	_, destination, err := net.ParseCIDR(route.DestinationCIDR)
+	if err != nil {
+		return false, err
+	}
+	cidrs, err := cloud.listVpcCIDRs(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, cidr := range cidrs {
+		if cidrsOverlap(destination, cidr.cidr) {
+			return false, &RouteCIDRConflictError{CIDR: route.DestinationCIDR, Conflict: cidr.owner + " " + cidr.cidr.String()}
+		}
+	}
+
+	existing, err := cloud.listTableRoutes(ctx, backend)
+	if err != nil {
+		return false, err
+	}
+	for _, other := range existing {
+		_, otherCIDR, err := net.ParseCIDR(other.DestinationCIDR)
+		if err != nil || !cidrContains(destination, otherCIDR) {
+			continue
+		}
+		if other.DestinationCIDR == route.DestinationCIDR && other.GatewayIP == route.GatewayIP {
+			return true, nil
+		}
+		return false, &RouteCIDRConflictError{CIDR: route.DestinationCIDR, Conflict: fmt.Sprintf("route %s via %s", other.DestinationCIDR, other.GatewayIP)}
+	}
+	return false, nil
+}
+
+// routeTableCacheKey returns the cache key of the routes of the route table of the config
+func (cloud *Cloud) routeTableCacheKey() string {
+	if cloud.txConfig.RouteBackend == RouteBackendVPC {
+		return cacheNamePreRouteTable + cloud.txConfig.RouteTableId
+	}
+	return cacheNamePreRouteTable + cloud.txConfig.ClusterRouteTable
+}
+
+// listTableRoutes returns the routes of the route table of backend, cached for the CreateRoute calls of a
+// route controller sync. Our own route changes invalidate them, see invalidateTableRoutesCache.
+func (cloud *Cloud) listTableRoutes(ctx context.Context, backend routeBackend) ([]*cloudRoute, error) {
+	cacheKey := cloud.routeTableCacheKey()
+	if routes, ok := cloud.tableRouteCache.Get(cacheKey); ok {
+		return routes, nil
+	}
+	routes, err := backend.ListTableRoutes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cloud.tableRouteCache.Set(cacheKey, routes)
+	return routes, nil
+}
+
+// invalidateTableRoutesCache evict the cached routes of the route table after a route is created or deleted
+func (cloud *Cloud) invalidateTableRoutesCache() {
+	if cacheKey := cloud.routeTableCacheKey(); cloud.tableRouteCache.Delete(cacheKey) {
+		klog.V(3).Infof("tencentcloud.invalidateTableRoutesCache: delete cache done. key: %s\n", cacheKey)
+	}
+}
+
+// recordRouteConflict emits a warning event of the route CIDR conflict on the target node
+func (cloud *Cloud) recordRouteConflict(ctx context.Context, nodeName types.NodeName, err *RouteCIDRConflictError) {
+	if cloud.eventRecorder == nil {
+		return
+	}
+	node, getErr := cloud.getNode(ctx, nodeName)
+	if getErr != nil {
+		klog.Warningf("tencentcloud.recordRouteConflict: get node %s error: %v\n", nodeName, getErr)
+		return
+	}
+	cloud.eventRecorder.Eventf(node, v1.EventTypeWarning, RouteConflictEventReason, "Pod CIDR route not created: %v", err)
+}
+
+// checkClusterCIDR check the comma separated CIDRs of --cluster-cidr do not overlap the vpc nor its subnets
+func (cloud *Cloud) checkClusterCIDR(ctx context.Context) error {
+	if strings.TrimSpace(cloud.txConfig.ClusterCIDR) == "" {
+		klog.V(3).Infof("tencentcloud.checkClusterCIDR: no --cluster-cidr, skipped\n")
+		return nil
+	}
+	cidrs, err := cloud.listVpcCIDRs(ctx)
+	if err != nil {
+		return err
+	}
+	for _, clusterCIDR := range strings.Split(cloud.txConfig.ClusterCIDR, ",") {
+		clusterCIDR = strings.TrimSpace(clusterCIDR)
+		_, ipNet, err := net.ParseCIDR(clusterCIDR)
+		if err != nil {
+			return fmt.Errorf("--cluster-cidr %s: %v", clusterCIDR, err)
+		}
+		for _, cidr := range cidrs {
+			if cidrsOverlap(ipNet, cidr.cidr) {
+				return &RouteCIDRConflictError{CIDR: clusterCIDR, Conflict: cidr.owner + " " + cidr.cidr.String()}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+package tencentcloud
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	cloudProvider "k8s.io/cloud-provider"
+	"k8s.io/klog"
+)
+
+const (
+	// defaultClusterName is the default --cluster-name of the controller manager
+	defaultClusterName = "kubernetes"
+
+	// RouteMissingEventReason is the reason of the node events of a pod CIDR route missing from the route table
+	RouteMissingEventReason = "RouteMissing"
+	// RouteExtraEventReason is the reason of the node events of a route to the node no pod CIDR of the node has
+	RouteExtraEventReason = "RouteExtra"
+
+	// routeCreatedReason is the reason of the route controller for a NetworkUnavailable false condition
+	routeCreatedReason = "RouteCreated"
+
+	defaultRouteHealthCheckInterval = 60 * time.Second
+)
+
+// RouteHealthCheckConfig configures the periodic comparison of the node pod CIDRs with the route table
+type RouteHealthCheckConfig struct {
+	// Enabled starts the check loop
+	Enabled bool `json:"enabled"`
+	// Interval is the interval between two checks, in seconds
+	Interval int `json:"interval"`
+}
+
+// routeHealth is the result of a route health check
+type routeHealth struct {
+	// missing are the pod CIDRs without a route to their node, by node name
+	missing map[string][]string
+	// extra are the routes of the cluster to a node without the pod CIDR, or to no node
+	extra []*routeHealthExtra
+}
+
+// routeHealthExtra is a route no node pod CIDR wants
+type routeHealthExtra struct {
+	destinationCIDR string
+	targetNode      types.NodeName
+	blackhole       bool
+}
+
+// runRouteHealthCheck compares the node pod CIDRs with the route table every interval until stop is closed
+func (cloud *Cloud) runRouteHealthCheck(interval time.Duration, stop <-chan struct{}) {
+	klog.V(3).Infof("tencentcloud.runRouteHealthCheck: check every %v\n", interval)
+	if !cloud.waitForNodeLister(stop) {
+		return
+	}
+	// extra routes are reported once, until they are gone
+	reported := make(map[string]bool)
+	wait.Until(func() {
+		if err := cloud.checkRouteHealth(context.TODO(), reported); err != nil {
+			klog.Warningf("tencentcloud.runRouteHealthCheck: check error: %v\n", err)
+		}
+	}, interval, stop)
+}
+
+// checkRouteHealth reports the missing and the extra routes of the cluster, and sets the NetworkUnavailable
+// condition of the nodes with a missing route. reported are the extra routes reported by the last check.
+func (cloud *Cloud) checkRouteHealth(ctx context.Context, reported map[string]bool) error {
+	klog.V(3).Infof("tencentcloud.checkRouteHealth(): entered\n")
+	nodes, err := cloud.listNodes(ctx)
+	if err != nil {
+		klog.Warningf("tencentcloud.checkRouteHealth: list nodes error: %v\n", err)
+		return err
+	}
+	// the check only reads the route table, adopting routes is up to the route controller
+	cloudRoutes, err := cloud.routeBackend().ListOwnedRoutes(ctx, cloud.txConfig.ClusterName)
+	if err != nil {
+		klog.Warningf("tencentcloud.checkRouteHealth: list routes error: %v\n", err)
+		return err
+	}
+	routes, err := cloud.getRouteTargetNodes(ctx, cloudRoutes)
+	if err != nil {
+		return err
+	}
+
+	health := cloud.compareRoutes(nodes, routes)
+	var missing int
+	for _, cidrs := range health.missing {
+		missing += len(cidrs)
+	}
+	routeHealthMissingRoutes.Set(float64(missing))
+	routeHealthExtraRoutes.Set(float64(len(health.extra)))
+
+	nodesByName := make(map[string]*v1.Node, len(nodes))
+	for _, node := range nodes {
+		nodesByName[node.Name] = node
+		if err := cloud.syncNodeNetworkUnavailable(ctx, node, health.missing[node.Name]); err != nil {
+			klog.Warningf("tencentcloud.checkRouteHealth: node %s patch error: %v\n", node.Name, err)
+		}
+	}
+
+	current := make(map[string]bool)
+	for _, extra := range health.extra {
+		key := extra.destinationCIDR + "/" + string(extra.targetNode)
+		current[key] = true
+		if reported[key] {
+			continue
+		}
+		if extra.blackhole {
+			klog.Warningf("tencentcloud.checkRouteHealth: route %s is a blackhole, its next hop has no instance\n", extra.destinationCIDR)
+			continue
+		}
+		klog.Warningf("tencentcloud.checkRouteHealth: route %s to node %s is not a pod CIDR of the node\n", extra.destinationCIDR, extra.targetNode)
+		// the next hop of a route to a deleted node has no node to record the event on
+		if node, ok := nodesByName[string(extra.targetNode)]; ok && cloud.eventRecorder != nil {
+			cloud.eventRecorder.Eventf(node, v1.EventTypeWarning, RouteExtraEventReason, "Route %s to the node is not a pod CIDR of the node", extra.destinationCIDR)
+		}
+	}
+	for key := range reported {
+		if !current[key] {
+			delete(reported, key)
+		}
+	}
+	for key := range current {
+		reported[key] = true
+	}
+	return nil
+}
+
+// compareRoutes returns the pod CIDRs of nodes without a route to the node and the routes to no pod CIDR of their node.
+// IPv6 pod CIDRs are only wanted with the vpc-route-table backend.
+func (cloud *Cloud) compareRoutes(nodes []*v1.Node, routes []*cloudProvider.Route) *routeHealth {
+	actual := make(map[string]bool)
+	for _, route := range routes {
+		if !route.Blackhole {
+			actual[route.DestinationCIDR+"/"+string(route.TargetNode)] = true
+		}
+	}
+
+	health := &routeHealth{missing: make(map[string][]string)}
+	desired := make(map[string]bool)
+	for _, node := range nodes {
+		for _, cidr := range nodePodCIDRs(node) {
+			if isIPv6CIDR(cidr) && cloud.txConfig.RouteBackend != RouteBackendVPC {
+				continue
+			}
+			desired[cidr+"/"+node.Name] = true
+			if !actual[cidr+"/"+node.Name] {
+				health.missing[node.Name] = append(health.missing[node.Name], cidr)
+			}
+		}
+	}
+	for _, route := range routes {
+		if route.Blackhole || !desired[route.DestinationCIDR+"/"+string(route.TargetNode)] {
+			health.extra = append(health.extra, &routeHealthExtra{
+				destinationCIDR: route.DestinationCIDR,
+				targetNode:      route.TargetNode,
+				blackhole:       route.Blackhole,
+			})
+		}
+	}
+	return health
+}
+
+// nodePodCIDRs returns the pod CIDRs of node, the dual-stack PodCIDRs or the PodCIDR
+func nodePodCIDRs(node *v1.Node) []string {
+	if len(node.Spec.PodCIDRs) > 0 {
+		return node.Spec.PodCIDRs
+	}
+	if node.Spec.PodCIDR != "" {
+		return []string{node.Spec.PodCIDR}
+	}
+	return nil
+}
+
+// syncNodeNetworkUnavailable sets the NetworkUnavailable condition of node when it has missing routes,
+// and clears the condition set by a previous check once the routes are back
+func (cloud *Cloud) syncNodeNetworkUnavailable(ctx context.Context, node *v1.Node, missing []string) error {
+	now := metav1.Now()
+	condition := v1.NodeCondition{
+		Type:               v1.NodeNetworkUnavailable,
+		Status:             v1.ConditionTrue,
+		LastHeartbeatTime:  now,
+		LastTransitionTime: now,
+		Reason:             RouteMissingEventReason,
+		Message:            fmt.Sprintf("Routes of pod CIDRs %v are missing from the route table", missing),
+	}
+	if len(missing) == 0 {
+		// only clear what we set, the route controller owns the condition otherwise
+		existing := getNodeCondition(node, v1.NodeNetworkUnavailable)
+		if existing == nil || existing.Status != v1.ConditionTrue || existing.Reason != RouteMissingEventReason {
+			return nil
+		}
+		condition.Status = v1.ConditionFalse
+		condition.Reason = routeCreatedReason
+		condition.Message = "Routes of the pod CIDRs are back in the route table"
+	}
+
+	updated := node.DeepCopy()
+	if !setNodeCondition(updated, condition) {
+		return nil
+	}
+	if err := cloud.patchNodeStatus(ctx, node, updated); err != nil {
+		return err
+	}
+	klog.V(3).Infof("tencentcloud.syncNodeNetworkUnavailable: node %s NetworkUnavailable %s\n", node.Name, condition.Status)
+	if len(missing) > 0 && cloud.eventRecorder != nil {
+		cloud.eventRecorder.Eventf(node, v1.EventTypeWarning, RouteMissingEventReason, "Routes of pod CIDRs %v are missing from the route table", missing)
+	}
+	return nil
+}
+
+// getNodeCondition returns the condition of node with conditionType, nil when the node has none
+func getNodeCondition(node *v1.Node, conditionType v1.NodeConditionType) *v1.NodeCondition {
+	for i := range node.Status.Conditions {
+		if node.Status.Conditions[i].Type == conditionType {
+			return &node.Status.Conditions[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package tencentcloud
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	vpc "github.com/weimob-tech/cloud-provider-tencent/pkg/vpc/v20170312"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/component-base/metrics/testutil"
+)
+
+func TestCheckRouteHealth(t *testing.T) {
+	cloud, fakeVpc := newTestVPCRouteCloud()
+	cloud.cvm = newFakeCVM(newTestInstance("ins-1", "10.0.0.1"), newTestInstance("ins-2", "10.0.0.2"), newTestInstance("ins-3", "10.0.0.3"))
+	cloud.txConfig.ClusterName = "kubernetes"
+	recorder := record.NewFakeRecorder(10)
+	cloud.eventRecorder = recorder
+	cloud.kubeClient = fake.NewSimpleClientset(
+		&v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "10.0.0.1"},
+			Spec:       v1.NodeSpec{PodCIDR: "172.16.0.0/24"},
+			Status:     v1.NodeStatus{Addresses: []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: "10.0.0.1"}}},
+		},
+		&v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "10.0.0.2"},
+			Spec:       v1.NodeSpec{PodCIDR: "172.16.1.0/24"},
+			Status:     v1.NodeStatus{Addresses: []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: "10.0.0.2"}}},
+		},
+	)
+	for cidr, gateway := range map[string]string{
+		"172.16.0.0/24": "10.0.0.1",
+		"172.16.1.0/24": "10.0.0.2",
+		"172.16.9.0/24": "10.0.0.1",
+		// the instance of 10.0.0.3 has no node
+		"172.16.8.0/24": "10.0.0.3",
+	} {
+		fakeVpc.CreateRoutes(&vpc.CreateRoutesRequest{
+			RouteTableId: common.StringPtr("rtb-test"),
+			Routes: []*vpc.Route{{
+				DestinationCidrBlock: common.StringPtr(cidr),
+				GatewayType:          common.StringPtr(routeGatewayTypeNormalCVM),
+				GatewayId:            common.StringPtr(gateway),
+				RouteDescription:     common.StringPtr(routeOwnerDescription("kubernetes")),
+			}},
+		})
+	}
+	// the route of 10.0.0.2 is deleted by hand
+	for _, route := range fakeVpc.routes("rtb-test") {
+		if *route.DestinationCidrBlock == "172.16.1.0/24" {
+			fakeVpc.DeleteRoutes(&vpc.DeleteRoutesRequest{RouteTableId: common.StringPtr("rtb-test"), Routes: []*vpc.Route{{RouteItemId: route.RouteItemId}}})
+		}
+	}
+
+	kubeClient := cloud.kubeClient.(*fake.Clientset)
+	reported := make(map[string]bool)
+	for i := 0; i < 2; i++ {
+		if err := cloud.checkRouteHealth(context.Background(), reported); err != nil {
+			t.Fatalf("checkRouteHealth error: %v", err)
+		}
+	}
+	if value, _ := testutil.GetGaugeMetricValue(routeHealthMissingRoutes); value != 1 {
+		t.Errorf("missing routes = %v, want 1", value)
+	}
+	if value, _ := testutil.GetGaugeMetricValue(routeHealthExtraRoutes); value != 2 {
+		t.Errorf("extra routes = %v, want 2", value)
+	}
+	// the condition is patched, the conditions of others are kept
+	for _, action := range kubeClient.Actions() {
+		if action.GetVerb() == "update" {
+			t.Errorf("unexpected %s %s/%s, want a patch", action.GetVerb(), action.GetResource().Resource, action.GetSubresource())
+		}
+	}
+	// one event per problem, not one per check, and none without a node to record it on
+	if len(recorder.Events) != 2 {
+		t.Fatalf("%d events, want 2", len(recorder.Events))
+	}
+	for _, reason := range []string{RouteMissingEventReason, RouteExtraEventReason} {
+		if event := <-recorder.Events; !strings.Contains(event, reason) {
+			t.Errorf("event = %q, want reason %s", event, reason)
+		}
+	}
+
+	node, err := cloud.kubeClient.CoreV1().Nodes().Get(context.Background(), "10.0.0.2", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if condition := getNodeCondition(node, v1.NodeNetworkUnavailable); condition == nil || condition.Status != v1.ConditionTrue {
+		t.Errorf("NetworkUnavailable of 10.0.0.2 = %+v, want true", condition)
+	}
+	node, err = cloud.kubeClient.CoreV1().Nodes().Get(context.Background(), "10.0.0.1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if condition := getNodeCondition(node, v1.NodeNetworkUnavailable); condition != nil {
+		t.Errorf("NetworkUnavailable of 10.0.0.1 = %+v, want none", condition)
+	}
+
+	// the route is back
+	fakeVpc.CreateRoutes(&vpc.CreateRoutesRequest{
+		RouteTableId: common.StringPtr("rtb-test"),
+		Routes: []*vpc.Route{{
+			DestinationCidrBlock: common.StringPtr("172.16.1.0/24"),
+			GatewayType:          common.StringPtr(routeGatewayTypeNormalCVM),
+			GatewayId:            common.StringPtr("10.0.0.2"),
+			RouteDescription:     common.StringPtr(routeOwnerDescription("kubernetes")),
+		}},
+	})
+	if err := cloud.checkRouteHealth(context.Background(), reported); err != nil {
+		t.Fatalf("checkRouteHealth error: %v", err)
+	}
+	if value, _ := testutil.GetGaugeMetricValue(routeHealthMissingRoutes); value != 0 {
+		t.Errorf("missing routes = %v, want 0", value)
+	}
+	node, err = cloud.kubeClient.CoreV1().Nodes().Get(context.Background(), "10.0.0.2", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if condition := getNodeCondition(node, v1.NodeNetworkUnavailable); condition == nil || condition.Status != v1.ConditionFalse || condition.Reason != routeCreatedReason {
+		t.Errorf("NetworkUnavailable of 10.0.0.2 = %+v, want false", condition)
+	}
+
+	// the check adopts no route, the route controller does
+	cloud.txConfig.AdoptUnownedRoutes = true
+	cloud.txConfig.ClusterCIDR = "172.16.0.0/16"
+	fakeVpc.CreateRoutes(&vpc.CreateRoutesRequest{
+		RouteTableId: common.StringPtr("rtb-test"),
+		Routes: []*vpc.Route{{
+			DestinationCidrBlock: common.StringPtr("172.16.0.0/25"),
+			GatewayType:          common.StringPtr(routeGatewayTypeNormalCVM),
+			GatewayId:            common.StringPtr("10.0.0.1"),
+		}},
+	})
+	if err := cloud.checkRouteHealth(context.Background(), reported); err != nil {
+		t.Fatalf("checkRouteHealth error: %v", err)
+	}
+	if calls := fakeVpc.callCount("ReplaceRoutes"); calls != 0 {
+		t.Errorf("ReplaceRoutes called %d times by the check, want 0", calls)
+	}
+}
@@ -0,0 +1,133 @@
+package tencentcloud
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	vpc "github.com/weimob-tech/cloud-provider-tencent/pkg/vpc/v20170312"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog"
+)
+
+// routeOwnerDescriptionPrefix prefixes the description of the vpc routes owned by a cluster
+const routeOwnerDescriptionPrefix = "kubernetes.io/cluster/"
+
+// routeOwnerDescription returns the description of the vpc routes owned by clusterName
+func routeOwnerDescription(clusterName string) string {
+	return routeOwnerDescriptionPrefix + clusterName
+}
+
+// adoptRoutes sets the owner description of the unowned routes of the cluster, i.e. the routes created before
+// the routes had an owner, and returns them. A route is the cluster's when its next hop is an InternalIP of a node
+// and its destination is a pod CIDR of a node or lies inside --cluster-cidr, other routes via a node are left alone.
+func (b *vpcRouteBackend) adoptRoutes(ctx context.Context, clusterName string, unowned []*vpc.Route) ([]*cloudRoute, error) {
+	nodes, err := b.cloud.listNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	nodeIPs := getNodeNamesByInternalIP(nodes)
+	podCIDRs := make(map[string]bool)
+	for _, node := range nodes {
+		for _, cidr := range nodePodCIDRs(node) {
+			podCIDRs[cidr] = true
+		}
+	}
+	clusterCIDRs := parseClusterCIDRs(b.cloud.txConfig.ClusterCIDR)
+
+	description := routeOwnerDescription(clusterName)
+	replaced := make([]*vpc.Route, 0)
+	adopted := make([]*cloudRoute, 0)
+	for _, route := range unowned {
+		if _, ok := nodeIPs[*route.GatewayId]; !ok {
+			continue
+		}
+		destination := routeDestination(route)
+		if !podCIDRs[destination] && !inClusterCIDRs(destination, clusterCIDRs) {
+			klog.V(3).Infof("tencentcloud.adoptRoutes: route %s via %s is no pod CIDR route, not adopted\n", destination, *route.GatewayId)
+			continue
+		}
+		replaced = append(replaced, &vpc.Route{
+			RouteItemId:              route.RouteItemId,
+			DestinationCidrBlock:     route.DestinationCidrBlock,
+			DestinationIpv6CidrBlock: route.DestinationIpv6CidrBlock,
+			GatewayType:              route.GatewayType,
+			GatewayId:                route.GatewayId,
+			RouteDescription:         common.StringPtr(description),
+		})
+		adopted = append(adopted, &cloudRoute{
+			DestinationCIDR: destination,
+			GatewayIP:       *route.GatewayId,
+			RouteItemID:     *route.RouteItemId,
+		})
+	}
+	if len(replaced) == 0 {
+		return adopted, nil
+	}
+
+	request := vpc.NewReplaceRoutesRequest()
+	request.RouteTableId = common.StringPtr(b.cloud.txConfig.RouteTableId)
+	request.Routes = replaced
+	request.SetContext(ctx)
+	if _, err := b.cloud.vpc.ReplaceRoutes(request); err != nil {
+		klog.Warningf("tencentcloud.adoptRoutes: tencentcloud API error: %v\n", err)
+		return nil, err
+	}
+	for _, route := range adopted {
+		klog.Infof("tencentcloud.adoptRoutes: route %s via %s adopted by cluster %s\n", route.DestinationCIDR, route.GatewayIP, clusterName)
+	}
+	return adopted, nil
+}
+
+// getNodeInternalIPs returns the name of the node of every InternalIP, by ip
+func (cloud *Cloud) getNodeInternalIPs(ctx context.Context) (map[string]string, error) {
+	nodes, err := cloud.listNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return getNodeNamesByInternalIP(nodes), nil
+}
+
+// getNodeNamesByInternalIP returns the names of nodes, by InternalIP
+func getNodeNamesByInternalIP(nodes []*v1.Node) map[string]string {
+	ips := make(map[string]string)
+	for _, node := range nodes {
+		for _, address := range node.Status.Addresses {
+			if address.Type == v1.NodeInternalIP {
+				ips[address.Address] = node.Name
+			}
+		}
+	}
+	return ips
+}
+
+// parseClusterCIDRs returns the CIDRs of the comma separated --cluster-cidr, the invalid ones are skipped
+func parseClusterCIDRs(clusterCIDR string) []*net.IPNet {
+	cidrs := make([]*net.IPNet, 0)
+	for _, cidr := range strings.Split(clusterCIDR, ",") {
+		if cidr = strings.TrimSpace(cidr); cidr == "" {
+			continue
+		}
+		if _, ipNet, err := net.ParseCIDR(cidr); err == nil {
+			cidrs = append(cidrs, ipNet)
+		}
+	}
+	return cidrs
+}
+
+// inClusterCIDRs check cidr lies inside one of clusterCIDRs
+func inClusterCIDRs(cidr string, clusterCIDRs []*net.IPNet) bool {
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return false
+	}
+	ones, bits := ipNet.Mask.Size()
+	for _, clusterCIDR := range clusterCIDRs {
+		clusterOnes, clusterBits := clusterCIDR.Mask.Size()
+		if bits == clusterBits && ones >= clusterOnes && clusterCIDR.Contains(ipNet.IP) {
+			return true
+		}
+	}
+	return false
+}
@@ -2,22 +2,72 @@ package tencentcloud
 
 import (
 	"context"
+	"fmt"
+	"net"
 
 	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
 	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/errors"
+	cvm "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/cvm/v20170312"
 	tke "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/tke/v20180525"
+	vpc "github.com/weimob-tech/cloud-provider-tencent/pkg/vpc/v20170312"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	cloudProvider "k8s.io/cloud-provider"
 	"k8s.io/klog"
 )
 
+// route backends, i.e. where the pod CIDR routes are managed
+const (
+	// RouteBackendTKE manages routes in a TKE cluster route table, created beforehand with route-ctl
+	RouteBackendTKE = "tke-cluster-route"
+	// RouteBackendVPC manages routes in a VPC route table, the instance of the node as next hop
+	RouteBackendVPC = "vpc-route-table"
+
+	// routeGatewayTypeNormalCVM is the vpc route next hop type of a CVM private ip
+	routeGatewayTypeNormalCVM = "NORMAL_CVM"
+)
+
+// cloudRoute is a pod CIDR route of a route backend
+type cloudRoute struct {
+	// DestinationCIDR is an ipv4 or an ipv6 CIDR
+	DestinationCIDR string
+	// GatewayIP is the private ip of the next hop instance, of the family of DestinationCIDR
+	GatewayIP string
+	// RouteItemID identifies the route in a VPC route table, empty for the TKE backend. The RouteId of the
+	// ipv6 routes is always 0, only the RouteItemId is unique.
+	RouteItemID string
+}
+
+// routeBackend creates and deletes pod CIDR routes
+type routeBackend interface {
+	// ListRoutes lists the routes owned by clusterName, adopting the unowned routes of the cluster when configured
+	ListRoutes(ctx context.Context, clusterName string) ([]*cloudRoute, error)
+	// ListOwnedRoutes lists the routes owned by clusterName and changes no route
+	ListOwnedRoutes(ctx context.Context, clusterName string) ([]*cloudRoute, error)
+	// ListTableRoutes lists every route of the route table, whatever its owner or next hop type
+	ListTableRoutes(ctx context.Context) ([]*cloudRoute, error)
+	CreateRoute(ctx context.Context, clusterName string, route *cloudRoute) error
+	DeleteRoute(ctx context.Context, clusterName string, route *cloudRoute) error
+}
+
+// isValidRouteBackend check the route backend is supported
+func isValidRouteBackend(backend string) bool {
+	return backend == RouteBackendTKE || backend == RouteBackendVPC
+}
+
+// routeBackend returns the route backend of the config
+func (cloud *Cloud) routeBackend() routeBackend {
+	if cloud.txConfig.RouteBackend == RouteBackendVPC {
+		return &vpcRouteBackend{cloud: cloud}
+	}
+	return &tkeRouteBackend{cloud: cloud}
+}
+
 // ListRoutes lists all managed routes that belong to the specified clusterName
 func (cloud *Cloud) ListRoutes(ctx context.Context, clusterName string) ([]*cloudProvider.Route, error) {
 	klog.V(3).Infof("tencentcloud.ListRoutes(\"%s\"): entered\n", clusterName)
-	request := tke.NewDescribeClusterRoutesRequest()
-	request.RouteTableName = common.StringPtr(cloud.txConfig.ClusterRouteTable)
-
-	cloudRoutes, err := cloud.tke.DescribeClusterRoutes(request)
+	cloudRoutes, err := cloud.routeBackend().ListRoutes(ctx, clusterName)
 	if _, ok := err.(*errors.TencentCloudSDKError); ok {
 		klog.Warningf("tencentcloud.ListRoutes: tencentcloud API error: %s\n", err)
 		klog.V(3).Infof("tencentcloud.ListRoutes: return: {}, %v\n", err)
@@ -30,9 +80,11 @@ func (cloud *Cloud) ListRoutes(ctx context.Context, clusterName string) ([]*clou
 		return []*cloudProvider.Route{}, err
 	}
 
-	routes := make([]*cloudProvider.Route, len(cloudRoutes.Response.RouteSet))
-	for idx, route := range cloudRoutes.Response.RouteSet {
-		routes[idx] = &cloudProvider.Route{Name: *route.GatewayIp, TargetNode: types.NodeName(*route.GatewayIp), DestinationCIDR: *route.DestinationCidrBlock}
+	routes, err := cloud.getRouteTargetNodes(ctx, cloudRoutes)
+	if err != nil {
+		klog.Warningf("tencentcloud.ListRoutes: Get error: %s\n", err)
+		klog.V(3).Infof("tencentcloud.ListRoutes: return: {}, %v\n", err)
+		return []*cloudProvider.Route{}, err
 	}
 
 	klog.V(3).Infof("tencentcloud.ListRoutes: return: %T, nil\n", routes)
@@ -44,12 +96,28 @@ func (cloud *Cloud) ListRoutes(ctx context.Context, clusterName string) ([]*clou
 // to create a more user-meaningful name.
 func (cloud *Cloud) CreateRoute(ctx context.Context, clusterName string, nameHint string, route *cloudProvider.Route) error {
 	klog.V(3).Infof("tencentcloud.CreateRoute(\"%s, %s, %T\"): entered\n", clusterName, nameHint, route)
-	request := tke.NewCreateClusterRouteRequest()
-	request.RouteTableName = common.StringPtr(cloud.txConfig.ClusterRouteTable)
-	request.GatewayIp = common.StringPtr(string(route.TargetNode))
-	request.DestinationCidrBlock = common.StringPtr(route.DestinationCIDR)
-
-	_, err := cloud.tke.CreateClusterRoute(request)
+	ipv6 := isIPv6CIDR(route.DestinationCIDR)
+	if ipv6 && cloud.txConfig.RouteBackend != RouteBackendVPC {
+		err := fmt.Errorf("ipv6 route %s: the %s route backend supports ipv4 routes only, use %s", route.DestinationCIDR, RouteBackendTKE, RouteBackendVPC)
+		klog.Warningf("tencentcloud.CreateRoute: Get error: %s\n", err)
+		return err
+	}
+	backend := cloud.routeBackend()
+	gatewayIP, err := cloud.getNodeInternalIP(ctx, route.TargetNode, ipv6)
+	var exists bool
+	if err == nil {
+		newRoute := &cloudRoute{DestinationCIDR: route.DestinationCIDR, GatewayIP: gatewayIP}
+		exists, err = cloud.checkRouteConflict(ctx, backend, newRoute)
+		if exists {
+			klog.V(3).Infof("tencentcloud.CreateRoute: route %s via %s exists already\n", route.DestinationCIDR, gatewayIP)
+		} else if err == nil {
+			err = backend.CreateRoute(ctx, clusterName, newRoute)
+			cloud.invalidateTableRoutesCache()
+		}
+	}
+	if conflict, ok := err.(*RouteCIDRConflictError); ok {
+		cloud.recordRouteConflict(ctx, route.TargetNode, conflict)
+	}
 	if err != nil {
 		klog.Warningf("tencentcloud.CreateRoute: Get error: %s\n", err)
 	}
@@ -62,16 +130,386 @@ func (cloud *Cloud) CreateRoute(ctx context.Context, clusterName string, nameHin
 // Route should be as returned by ListRoutes
 func (cloud *Cloud) DeleteRoute(ctx context.Context, clusterName string, route *cloudProvider.Route) error {
 	klog.V(3).Infof("tencentcloud.DeleteRoute(\"%s, %T\"): entered\n", clusterName, route)
+	// ListRoutes names routes after their gateway ip, the target node may be gone already
+	gatewayIP := route.Name
+	var err error
+	if net.ParseIP(gatewayIP) == nil {
+		gatewayIP, err = cloud.getNodeInternalIP(ctx, route.TargetNode, isIPv6CIDR(route.DestinationCIDR))
+	}
+	if err == nil {
+		err = cloud.routeBackend().DeleteRoute(ctx, clusterName, &cloudRoute{
+			DestinationCIDR: route.DestinationCIDR,
+			GatewayIP:       gatewayIP,
+		})
+		cloud.invalidateTableRoutesCache()
+	}
+	if err != nil {
+		klog.Warningf("tencentcloud.DeleteRoute: Get error: %s\n", err)
+	}
+
+	klog.V(3).Infof("tencentcloud.DeleteRoute: return: %v\n", err)
+	return err
+}
+
+// getRouteTargetNodes returns the routes of cloudRoutes with the node of their gateway ip as target. A gateway ip
+// is an InternalIP of its node, or a private ip of the instance of its node for the nodes without addresses yet,
+// routes via an ip no node nor instance has are blackholes. Instances can not be looked up by ipv6 address,
+// ipv6 gateways only match node InternalIPs.
+// k8s.io/cloud-provider v0.18 has no Route.TargetNodeAddresses, the route controller matches routes by node name.
+func (cloud *Cloud) getRouteTargetNodes(ctx context.Context, cloudRoutes []*cloudRoute) ([]*cloudProvider.Route, error) {
+	nodes, err := cloud.listNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	nodeNamesByIP := getNodeNamesByInternalIP(nodes)
+
+	// only the gateways of no node InternalIP are looked up
+	gatewayIPs := make([]string, 0)
+	for _, route := range cloudRoutes {
+		if _, ok := nodeNamesByIP[route.GatewayIP]; ok {
+			continue
+		}
+		if ip := net.ParseIP(route.GatewayIP); ip != nil && ip.To4() != nil {
+			gatewayIPs = append(gatewayIPs, route.GatewayIP)
+		}
+	}
+	instances, err := cloud.getInstanceByInstancePrivateIps(ctx, gatewayIPs)
+	if err != nil {
+		return nil, err
+	}
+	instanceByIP := make(map[string]*cvm.Instance)
+	for _, instance := range instances {
+		for _, ip := range instance.PrivateIpAddresses {
+			instanceByIP[*ip] = instance
+		}
+	}
+	nodeNames := getNodeNamesByInstanceID(nodes)
+
+	routes := make([]*cloudProvider.Route, 0, len(cloudRoutes))
+	for _, route := range cloudRoutes {
+		if nodeName, ok := nodeNamesByIP[route.GatewayIP]; ok {
+			routes = append(routes, &cloudProvider.Route{Name: route.GatewayIP, TargetNode: types.NodeName(nodeName), DestinationCIDR: route.DestinationCIDR})
+			continue
+		}
+		instance, ok := instanceByIP[route.GatewayIP]
+		if !ok {
+			klog.Warningf("tencentcloud.getRouteTargetNodes: route %s via %s has no instance\n", route.DestinationCIDR, route.GatewayIP)
+			routes = append(routes, &cloudProvider.Route{Name: route.GatewayIP, DestinationCIDR: route.DestinationCIDR, Blackhole: true})
+			continue
+		}
+		nodeName, ok := nodeNames[*instance.InstanceId]
+		if !ok {
+			nodeName, ok = cloud.getInstanceNodeName(instance, nodeNamesByIP)
+		}
+		if !ok {
+			klog.Warningf("tencentcloud.getRouteTargetNodes: route %s via %s has no node\n", route.DestinationCIDR, route.GatewayIP)
+			routes = append(routes, &cloudProvider.Route{Name: route.GatewayIP, DestinationCIDR: route.DestinationCIDR, Blackhole: true})
+			continue
+		}
+		routes = append(routes, &cloudProvider.Route{Name: route.GatewayIP, TargetNode: types.NodeName(nodeName), DestinationCIDR: route.DestinationCIDR})
+	}
+	return routes, nil
+}
+
+// getNodeNamesByInstanceID returns the names of the nodes with a providerID, by instance id
+func getNodeNamesByInstanceID(nodes []*v1.Node) map[string]string {
+	nodeNames := make(map[string]string)
+	for _, node := range nodes {
+		if providerID, err := ParseProviderID(node.Spec.ProviderID); err == nil {
+			nodeNames[providerID.InstanceID] = node.Name
+		}
+	}
+	return nodeNames
+}
+
+// getInstanceNodeName returns the node name of instance according to the node name strategy. A hostname can not be
+// derived from the instance, the hostname strategy looks the node up by the private ips of the instance in
+// nodeNamesByIP, the node names by InternalIP, ok is false when no node has one of them.
+func (cloud *Cloud) getInstanceNodeName(instance *cvm.Instance, nodeNamesByIP map[string]string) (string, bool) {
+	switch cloud.txConfig.NodeNameStrategy {
+	case NodeNameStrategyInstanceID:
+		return *instance.InstanceId, true
+	case NodeNameStrategyInstanceName:
+		return *instance.InstanceName, true
+	case NodeNameStrategyHostname:
+		for _, ip := range instance.PrivateIpAddresses {
+			if nodeName, ok := nodeNamesByIP[*ip]; ok {
+				return nodeName, true
+			}
+		}
+		return "", false
+	default:
+		return *instance.PrivateIpAddresses[0], true
+	}
+}
+
+// getNodeInternalIP returns the first InternalIP of the node of the ip family. An ipv4 node without one falls back
+// to the primary private ip of its instance, as getRouteTargetNodes resolves ipv4 gateways by instance too. Instances
+// can not be looked up by ipv6 address, an ipv6 gateway is an InternalIP of the node or the route would be a blackhole.
+func (cloud *Cloud) getNodeInternalIP(ctx context.Context, nodeName types.NodeName, ipv6 bool) (string, error) {
+	node, err := cloud.getNode(ctx, nodeName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return "", err
+	}
+	if err == nil {
+		for _, address := range node.Status.Addresses {
+			ip := net.ParseIP(address.Address)
+			if address.Type == v1.NodeInternalIP && ip != nil && (ip.To4() == nil) == ipv6 {
+				return address.Address, nil
+			}
+		}
+	}
+	if ipv6 {
+		return "", fmt.Errorf("node %s has no ipv6 InternalIP", nodeName)
+	}
+
+	instance, err := cloud.getInstanceByNodeName(ctx, nodeName)
+	if err != nil {
+		return "", err
+	}
+	if len(instance.PrivateIpAddresses) == 0 {
+		return "", fmt.Errorf("instance %s of node %s has no private ip", *instance.InstanceId, nodeName)
+	}
+	return *instance.PrivateIpAddresses[0], nil
+}
+
+// isIPv6CIDR check cidr is an ipv6 CIDR
+func isIPv6CIDR(cidr string) bool {
+	ip, _, err := net.ParseCIDR(cidr)
+	return err == nil && ip.To4() == nil
+}
+
+// tkeRouteBackend manages routes with the TKE cluster route api. Cluster routes have no description, a route of
+// the cluster route table is owned by the cluster when its destination lies inside --cluster-cidr, or without
+// --cluster-cidr when its next hop is an InternalIP of a node of the cluster. Cluster routes are ipv4 only.
+type tkeRouteBackend struct {
+	cloud *Cloud
+}
+
+func (b *tkeRouteBackend) ListRoutes(ctx context.Context, clusterName string) ([]*cloudRoute, error) {
+	tableRoutes, err := b.ListTableRoutes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	clusterCIDRs := parseClusterCIDRs(b.cloud.txConfig.ClusterCIDR)
+	var nodeIPs map[string]string
+	if len(clusterCIDRs) == 0 {
+		if nodeIPs, err = b.cloud.getNodeInternalIPs(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	routes := make([]*cloudRoute, 0, len(tableRoutes))
+	for _, route := range tableRoutes {
+		if len(clusterCIDRs) > 0 && !inClusterCIDRs(route.DestinationCIDR, clusterCIDRs) {
+			continue
+		}
+		if len(clusterCIDRs) == 0 {
+			if _, ok := nodeIPs[route.GatewayIP]; !ok {
+				continue
+			}
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
+
+// ListOwnedRoutes lists the routes of the cluster, cluster routes are never adopted
+func (b *tkeRouteBackend) ListOwnedRoutes(ctx context.Context, clusterName string) ([]*cloudRoute, error) {
+	return b.ListRoutes(ctx, clusterName)
+}
+
+// ListTableRoutes lists every route of the cluster route table
+func (b *tkeRouteBackend) ListTableRoutes(ctx context.Context) ([]*cloudRoute, error) {
+	request := tke.NewDescribeClusterRoutesRequest()
+	request.RouteTableName = common.StringPtr(b.cloud.txConfig.ClusterRouteTable)
+
+	request.SetContext(ctx)
+	response, err := b.cloud.tke.DescribeClusterRoutes(request)
+	if err != nil {
+		return nil, err
+	}
+	routes := make([]*cloudRoute, 0, len(response.Response.RouteSet))
+	for _, route := range response.Response.RouteSet {
+		routes = append(routes, &cloudRoute{DestinationCIDR: *route.DestinationCidrBlock, GatewayIP: *route.GatewayIp})
+	}
+	return routes, nil
+}
+
+func (b *tkeRouteBackend) CreateRoute(ctx context.Context, clusterName string, route *cloudRoute) error {
+	request := tke.NewCreateClusterRouteRequest()
+	request.RouteTableName = common.StringPtr(b.cloud.txConfig.ClusterRouteTable)
+	request.GatewayIp = common.StringPtr(route.GatewayIP)
+	request.DestinationCidrBlock = common.StringPtr(route.DestinationCIDR)
+
+	request.SetContext(ctx)
+	_, err := b.cloud.tke.CreateClusterRoute(request)
+	return err
+}
+
+func (b *tkeRouteBackend) DeleteRoute(ctx context.Context, clusterName string, route *cloudRoute) error {
 	request := tke.NewDeleteClusterRouteRequest()
-	request.RouteTableName = common.StringPtr(cloud.txConfig.ClusterRouteTable)
-	request.GatewayIp = common.StringPtr(string(route.TargetNode))
+	request.RouteTableName = common.StringPtr(b.cloud.txConfig.ClusterRouteTable)
+	request.GatewayIp = common.StringPtr(route.GatewayIP)
 	request.DestinationCidrBlock = common.StringPtr(route.DestinationCIDR)
 
-	_, err := cloud.tke.DeleteClusterRoute(request)
+	request.SetContext(ctx)
+	_, err := b.cloud.tke.DeleteClusterRoute(request)
+	return err
+}
+
+// vpcRouteBackend manages the NORMAL_CVM routes of a VPC route table, owned by the cluster named in their description.
+// IPv6 routes are routes with a DestinationIpv6CidrBlock via the ipv6 address of the instance.
+type vpcRouteBackend struct {
+	cloud *Cloud
+}
+
+// routeDestination returns the ipv4 or the ipv6 destination CIDR of route, "" when it has none
+func routeDestination(route *vpc.Route) string {
+	if route.DestinationCidrBlock != nil && *route.DestinationCidrBlock != "" {
+		return *route.DestinationCidrBlock
+	}
+	if route.DestinationIpv6CidrBlock != nil {
+		return *route.DestinationIpv6CidrBlock
+	}
+	return ""
+}
+
+// describeRouteTable returns the configured route table
+func (b *vpcRouteBackend) describeRouteTable(ctx context.Context) (*vpc.RouteTable, error) {
+	request := vpc.NewDescribeRouteTablesRequest()
+	request.RouteTableIds = common.StringPtrs([]string{b.cloud.txConfig.RouteTableId})
+	request.SetContext(ctx)
+
+	response, err := b.cloud.vpc.DescribeRouteTables(request)
 	if err != nil {
-		klog.Warningf("tencentcloud.DeleteRoute: Get error: %s\n", err)
+		return nil, err
+	}
+	for _, table := range response.Response.RouteTableSet {
+		if *table.RouteTableId == b.cloud.txConfig.RouteTableId {
+			return table, nil
+		}
 	}
+	return nil, fmt.Errorf("route table %s not found", b.cloud.txConfig.RouteTableId)
+}
 
-	klog.V(3).Infof("tencentcloud.DeleteRoute: return: %v\n", err)
+func (b *vpcRouteBackend) ListRoutes(ctx context.Context, clusterName string) ([]*cloudRoute, error) {
+	routes, unowned, err := b.listRoutes(ctx, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	if b.cloud.txConfig.AdoptUnownedRoutes && len(unowned) > 0 {
+		adopted, err := b.adoptRoutes(ctx, clusterName, unowned)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, adopted...)
+	}
+	return routes, nil
+}
+
+func (b *vpcRouteBackend) ListOwnedRoutes(ctx context.Context, clusterName string) ([]*cloudRoute, error) {
+	routes, _, err := b.listRoutes(ctx, clusterName)
+	return routes, err
+}
+
+// listRoutes returns the NORMAL_CVM routes of the route table owned by clusterName, and the ones without owner
+func (b *vpcRouteBackend) listRoutes(ctx context.Context, clusterName string) ([]*cloudRoute, []*vpc.Route, error) {
+	table, err := b.describeRouteTable(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	description := routeOwnerDescription(clusterName)
+	routes := make([]*cloudRoute, 0, len(table.RouteSet))
+	unowned := make([]*vpc.Route, 0)
+	for _, route := range table.RouteSet {
+		if route.GatewayType == nil || *route.GatewayType != routeGatewayTypeNormalCVM ||
+			routeDestination(route) == "" || route.GatewayId == nil || route.RouteItemId == nil {
+			continue
+		}
+		if route.RouteDescription == nil || *route.RouteDescription == "" {
+			unowned = append(unowned, route)
+			continue
+		}
+		if *route.RouteDescription != description {
+			continue
+		}
+		routes = append(routes, &cloudRoute{
+			DestinationCIDR: routeDestination(route),
+			GatewayIP:       *route.GatewayId,
+			RouteItemID:     *route.RouteItemId,
+		})
+	}
+	return routes, unowned, nil
+}
+
+func (b *vpcRouteBackend) ListTableRoutes(ctx context.Context) ([]*cloudRoute, error) {
+	table, err := b.describeRouteTable(ctx)
+	if err != nil {
+		return nil, err
+	}
+	routes := make([]*cloudRoute, 0, len(table.RouteSet))
+	for _, route := range table.RouteSet {
+		destination := routeDestination(route)
+		if destination == "" {
+			continue
+		}
+		existing := &cloudRoute{DestinationCIDR: destination}
+		if route.GatewayId != nil {
+			existing.GatewayIP = *route.GatewayId
+		}
+		if route.RouteItemId != nil {
+			existing.RouteItemID = *route.RouteItemId
+		}
+		routes = append(routes, existing)
+	}
+	return routes, nil
+}
+
+func (b *vpcRouteBackend) CreateRoute(ctx context.Context, clusterName string, route *cloudRoute) error {
+	request := vpc.NewCreateRoutesRequest()
+	request.RouteTableId = common.StringPtr(b.cloud.txConfig.RouteTableId)
+	newRoute := &vpc.Route{
+		GatewayType:      common.StringPtr(routeGatewayTypeNormalCVM),
+		GatewayId:        common.StringPtr(route.GatewayIP),
+		RouteDescription: common.StringPtr(routeOwnerDescription(clusterName)),
+	}
+	if isIPv6CIDR(route.DestinationCIDR) {
+		newRoute.DestinationIpv6CidrBlock = common.StringPtr(route.DestinationCIDR)
+	} else {
+		newRoute.DestinationCidrBlock = common.StringPtr(route.DestinationCIDR)
+	}
+	request.Routes = []*vpc.Route{newRoute}
+
+	request.SetContext(ctx)
+	_, err := b.cloud.vpc.CreateRoutes(request)
+	return err
+}
+
+func (b *vpcRouteBackend) DeleteRoute(ctx context.Context, clusterName string, route *cloudRoute) error {
+	// the route controller only knows the destination and the gateway, look the id of the owned route up
+	if route.RouteItemID == "" {
+		routes, err := b.ListRoutes(ctx, clusterName)
+		if err != nil {
+			return err
+		}
+		for _, existing := range routes {
+			if existing.DestinationCIDR == route.DestinationCIDR && existing.GatewayIP == route.GatewayIP {
+				route = existing
+				break
+			}
+		}
+		if route.RouteItemID == "" {
+			klog.V(3).Infof("tencentcloud.DeleteRoute: route %s via %s already deleted\n", route.DestinationCIDR, route.GatewayIP)
+			return nil
+		}
+	}
+
+	request := vpc.NewDeleteRoutesRequest()
+	request.RouteTableId = common.StringPtr(b.cloud.txConfig.RouteTableId)
+	request.Routes = []*vpc.Route{{RouteItemId: common.StringPtr(route.RouteItemID)}}
+
+	request.SetContext(ctx)
+	_, err := b.cloud.vpc.DeleteRoutes(request)
 	return err
 }
@@ -0,0 +1,519 @@
+package tencentcloud
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	tke "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/tke/v20180525"
+	vpc "github.com/weimob-tech/cloud-provider-tencent/pkg/vpc/v20170312"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	cloudProvider "k8s.io/cloud-provider"
+)
+
+func newTestVPCRouteCloud() (*Cloud, *fakeVPC) {
+	fakeVpc := newFakeVPC()
+	fakeVpc.addRouteTable("rtb-test")
+	fakeVpc.addVpc("10.0.0.0/16", "10.0.0.0/24", "10.0.1.0/24")
+	cloud := newTestCloud(newFakeCLB(), newFakeCVM(newTestInstance("ins-1", "10.0.0.1")))
+	cloud.vpc = fakeVpc
+	cloud.txConfig.RouteBackend = RouteBackendVPC
+	cloud.txConfig.RouteTableId = "rtb-test"
+	return cloud, fakeVpc
+}
+
+func TestVPCRouteBackend(t *testing.T) {
+	cloud, fakeVpc := newTestVPCRouteCloud()
+	route := &cloudProvider.Route{TargetNode: "10.0.0.1", DestinationCIDR: "172.16.0.0/24"}
+
+	if err := cloud.CreateRoute(context.Background(), "kubernetes", "hint", route); err != nil {
+		t.Fatalf("CreateRoute error: %v", err)
+	}
+	routes := fakeVpc.routes("rtb-test")
+	if len(routes) != 1 || *routes[0].GatewayType != routeGatewayTypeNormalCVM || *routes[0].GatewayId != "10.0.0.1" {
+		t.Fatalf("routes = %+v, want one NORMAL_CVM route via 10.0.0.1", routes)
+	}
+
+	listed, err := cloud.ListRoutes(context.Background(), "kubernetes")
+	if err != nil || len(listed) != 1 || listed[0].DestinationCIDR != "172.16.0.0/24" {
+		t.Fatalf("ListRoutes = %+v, %v, want the created route", listed, err)
+	}
+
+	if err := cloud.DeleteRoute(context.Background(), "kubernetes", listed[0]); err != nil {
+		t.Fatalf("DeleteRoute error: %v", err)
+	}
+	if routes := fakeVpc.routes("rtb-test"); len(routes) != 0 {
+		t.Errorf("routes after DeleteRoute = %+v, want none", routes)
+	}
+	// deleting a route twice is not an error
+	if err := cloud.DeleteRoute(context.Background(), "kubernetes", listed[0]); err != nil {
+		t.Errorf("DeleteRoute of a deleted route error: %v", err)
+	}
+}
+
+func TestRouteTargetNodes(t *testing.T) {
+	cloud, fakeVpc := newTestVPCRouteCloud()
+	cloud.txConfig.NodeNameStrategy = NodeNameStrategyHostname
+	cloud.kubeClient = fake.NewSimpleClientset(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-a"},
+		Spec:       v1.NodeSpec{ProviderID: "tencentcloud:///ap-guangzhou-3/ins-1"},
+		Status: v1.NodeStatus{Addresses: []v1.NodeAddress{
+			{Type: v1.NodeInternalIP, Address: "fd00::1"},
+			{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+		}},
+	})
+
+	// the gateway is the InternalIP of the node, whatever its name
+	if err := cloud.CreateRoute(context.Background(), "kubernetes", "hint", &cloudProvider.Route{TargetNode: "node-a", DestinationCIDR: "172.16.0.0/24"}); err != nil {
+		t.Fatalf("CreateRoute error: %v", err)
+	}
+	if routes := fakeVpc.routes("rtb-test"); len(routes) != 1 || *routes[0].GatewayId != "10.0.0.1" {
+		t.Fatalf("routes = %+v, want one route via 10.0.0.1", routes)
+	}
+	// a route via an ip no instance has
+	fakeVpc.CreateRoutes(&vpc.CreateRoutesRequest{
+		RouteTableId: common.StringPtr("rtb-test"),
+		Routes: []*vpc.Route{{
+			DestinationCidrBlock: common.StringPtr("172.16.1.0/24"),
+			GatewayType:          common.StringPtr(routeGatewayTypeNormalCVM),
+			GatewayId:            common.StringPtr("10.0.0.9"),
+			RouteDescription:     common.StringPtr(routeOwnerDescription("kubernetes")),
+		}},
+	})
+
+	fakeCvm := cloud.cvm.(*fakeCVM)
+	calls := fakeCvm.callCount()
+	kubeClient := cloud.kubeClient.(*fake.Clientset)
+	kubeClient.ClearActions()
+	routes, err := cloud.ListRoutes(context.Background(), "kubernetes")
+	if err != nil || len(routes) != 2 {
+		t.Fatalf("ListRoutes = %+v, %v, want 2 routes", routes, err)
+	}
+	// the nodes are listed once, only the gateway of no node InternalIP is looked up
+	if lists := len(kubeClient.Actions()); lists != 1 {
+		t.Errorf("ListRoutes made %d kubernetes calls, want 1 node list", lists)
+	}
+	if lookups := fakeCvm.callCount() - calls; lookups != 1 {
+		t.Errorf("ListRoutes called DescribeInstances %d times, want 1 for 10.0.0.9", lookups)
+	}
+	if routes[0].TargetNode != "node-a" || routes[0].Blackhole {
+		t.Errorf("route via 10.0.0.1 = %+v, want target node-a", routes[0])
+	}
+	if routes[1].TargetNode != "" || !routes[1].Blackhole {
+		t.Errorf("route via 10.0.0.9 = %+v, want a blackhole", routes[1])
+	}
+
+	// the gateway of a route is known after its node is gone
+	cloud.kubeClient.CoreV1().Nodes().Delete(context.Background(), "node-a", metav1.DeleteOptions{})
+	if err := cloud.DeleteRoute(context.Background(), "kubernetes", routes[0]); err != nil {
+		t.Fatalf("DeleteRoute error: %v", err)
+	}
+	if remaining := fakeVpc.routes("rtb-test"); len(remaining) != 1 || *remaining[0].GatewayId != "10.0.0.9" {
+		t.Errorf("routes after DeleteRoute = %+v, want the route via 10.0.0.9", remaining)
+	}
+}
+
+func TestGetInstanceNodeName(t *testing.T) {
+	instance := newTestInstance("ins-1", "10.0.0.1")
+	instance.InstanceName = common.StringPtr("name-1")
+	instance.PrivateIpAddresses = common.StringPtrs([]string{"10.0.0.1", "10.0.0.2"})
+	nodeNamesByIP := map[string]string{"10.0.0.2": "node-1.example.com"}
+
+	testCases := []struct {
+		strategy   string
+		nodeNames  map[string]string
+		expected   string
+		expectedOK bool
+	}{
+		{strategy: "", expected: "10.0.0.1", expectedOK: true},
+		{strategy: NodeNameStrategyInstanceID, expected: "ins-1", expectedOK: true},
+		{strategy: NodeNameStrategyInstanceName, expected: "name-1", expectedOK: true},
+		// the hostname is the name of the node with a private ip of the instance as InternalIP
+		{strategy: NodeNameStrategyHostname, nodeNames: nodeNamesByIP, expected: "node-1.example.com", expectedOK: true},
+		{strategy: NodeNameStrategyHostname},
+	}
+	for _, testCase := range testCases {
+		cloud := newTestCloud(newFakeCLB(), newFakeCVM())
+		cloud.txConfig.NodeNameStrategy = testCase.strategy
+		nodeName, ok := cloud.getInstanceNodeName(instance, testCase.nodeNames)
+		if nodeName != testCase.expected || ok != testCase.expectedOK {
+			t.Errorf("%q: getInstanceNodeName = %q, %v, want %q, %v", testCase.strategy, nodeName, ok, testCase.expected, testCase.expectedOK)
+		}
+	}
+}
+
+func TestVPCRouteBackendIPv6(t *testing.T) {
+	cloud, fakeVpc := newTestVPCRouteCloud()
+	cloud.kubeClient = fake.NewSimpleClientset(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "10.0.0.1"},
+		Status: v1.NodeStatus{Addresses: []v1.NodeAddress{
+			{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+			{Type: v1.NodeInternalIP, Address: "2402:4e00:1::1"},
+		}},
+	})
+	for _, cidr := range []string{"172.16.0.0/24", "fd00:10::/64", "fd00:13::/64"} {
+		if err := cloud.CreateRoute(context.Background(), "kubernetes", "hint", &cloudProvider.Route{TargetNode: "10.0.0.1", DestinationCIDR: cidr}); err != nil {
+			t.Fatalf("CreateRoute(%s) error: %v", cidr, err)
+		}
+	}
+	routes := fakeVpc.routes("rtb-test")
+	if len(routes) != 3 || routes[1].DestinationCidrBlock != nil || *routes[1].DestinationIpv6CidrBlock != "fd00:10::/64" || *routes[1].GatewayId != "2402:4e00:1::1" {
+		t.Fatalf("routes = %+v, want an ipv6 route via 2402:4e00:1::1", routes)
+	}
+
+	listed, err := cloud.ListRoutes(context.Background(), "kubernetes")
+	if err != nil || len(listed) != 3 {
+		t.Fatalf("ListRoutes = %+v, %v, want 3 routes", listed, err)
+	}
+	if listed[1].DestinationCIDR != "fd00:10::/64" || listed[1].TargetNode != "10.0.0.1" || listed[1].Blackhole {
+		t.Errorf("ipv6 route = %+v, want fd00:10::/64 to node 10.0.0.1", listed[1])
+	}
+	if err := cloud.DeleteRoute(context.Background(), "kubernetes", listed[1]); err != nil {
+		t.Fatalf("DeleteRoute error: %v", err)
+	}
+	// the ipv6 routes share the RouteId 0, the other one is kept
+	if remaining := fakeVpc.routes("rtb-test"); len(remaining) != 2 || *remaining[0].DestinationCidrBlock != "172.16.0.0/24" || *remaining[1].DestinationIpv6CidrBlock != "fd00:13::/64" {
+		t.Errorf("routes after DeleteRoute = %+v, want the ipv4 route and fd00:13::/64", remaining)
+	}
+
+	// ListRoutes resolves ipv6 gateways through node InternalIPs only, a node without one gets no route
+	if _, err := cloud.kubeClient.CoreV1().Nodes().Create(context.Background(), &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "10.0.0.2"},
+		Status:     v1.NodeStatus{Addresses: []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: "10.0.0.2"}}},
+	}, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("create node error: %v", err)
+	}
+	if err := cloud.CreateRoute(context.Background(), "kubernetes", "hint", &cloudProvider.Route{TargetNode: "10.0.0.2", DestinationCIDR: "fd00:12::/64"}); err == nil {
+		t.Errorf("CreateRoute of an ipv6 route to a node without ipv6 InternalIP = nil, want an error")
+	}
+	if routes := fakeVpc.routes("rtb-test"); len(routes) != 2 {
+		t.Errorf("routes = %+v, want no route to the node without ipv6 InternalIP", routes)
+	}
+
+	// the tke cluster route backend is ipv4 only
+	cloud.txConfig.RouteBackend = RouteBackendTKE
+	if err := cloud.CreateRoute(context.Background(), "kubernetes", "hint", &cloudProvider.Route{TargetNode: "10.0.0.1", DestinationCIDR: "fd00:11::/64"}); err == nil {
+		t.Errorf("CreateRoute of an ipv6 route with the tke backend = nil, want an error")
+	}
+}
+
+func TestRouteOwnership(t *testing.T) {
+	cloud, fakeVpc := newTestVPCRouteCloud()
+	cloud.kubeClient = fake.NewSimpleClientset(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "10.0.0.1"},
+		Spec:       v1.NodeSpec{PodCIDR: "172.16.2.0/24"},
+		Status:     v1.NodeStatus{Addresses: []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: "10.0.0.1"}}},
+	})
+	if err := cloud.CreateRoute(context.Background(), "kubernetes", "hint", &cloudProvider.Route{TargetNode: "10.0.0.1", DestinationCIDR: "172.16.0.0/24"}); err != nil {
+		t.Fatalf("CreateRoute error: %v", err)
+	}
+	for cidr, description := range map[string]string{
+		"172.16.1.0/24": routeOwnerDescription("other"),
+		"172.16.2.0/24": "",
+		"172.16.3.0/24": "manual",
+		// a manual route via the node, no pod CIDR
+		"192.168.100.0/24": "",
+	} {
+		fakeVpc.CreateRoutes(&vpc.CreateRoutesRequest{
+			RouteTableId: common.StringPtr("rtb-test"),
+			Routes: []*vpc.Route{{
+				DestinationCidrBlock: common.StringPtr(cidr),
+				GatewayType:          common.StringPtr(routeGatewayTypeNormalCVM),
+				GatewayId:            common.StringPtr("10.0.0.1"),
+				RouteDescription:     common.StringPtr(description),
+			}},
+		})
+	}
+
+	routes, err := cloud.ListRoutes(context.Background(), "kubernetes")
+	if err != nil || len(routes) != 1 || routes[0].DestinationCIDR != "172.16.0.0/24" {
+		t.Fatalf("ListRoutes = %+v, %v, want the owned route only", routes, err)
+	}
+	if err := cloud.DeleteRoute(context.Background(), "kubernetes", &cloudProvider.Route{Name: "10.0.0.1", DestinationCIDR: "172.16.1.0/24"}); err != nil {
+		t.Fatalf("DeleteRoute error: %v", err)
+	}
+	if routes := fakeVpc.routes("rtb-test"); len(routes) != 5 {
+		t.Errorf("%d routes after deleting a route of another cluster, want 5", len(routes))
+	}
+
+	// the route without description via a node to its pod CIDR is adopted
+	cloud.txConfig.AdoptUnownedRoutes = true
+	routes, err = cloud.ListRoutes(context.Background(), "kubernetes")
+	if err != nil || len(routes) != 2 || routes[1].DestinationCIDR != "172.16.2.0/24" {
+		t.Fatalf("ListRoutes adopting = %+v, %v, want the owned and the adopted route", routes, err)
+	}
+	for _, route := range fakeVpc.routes("rtb-test") {
+		if *route.DestinationCidrBlock == "172.16.2.0/24" && *route.RouteDescription != routeOwnerDescription("kubernetes") {
+			t.Errorf("adopted route description = %q, want %q", *route.RouteDescription, routeOwnerDescription("kubernetes"))
+		}
+		if *route.DestinationCidrBlock == "192.168.100.0/24" && *route.RouteDescription != "" {
+			t.Errorf("manual route description = %q, want it left unowned", *route.RouteDescription)
+		}
+	}
+
+	// a route inside --cluster-cidr is adopted too, the manual route never is
+	fakeVpc.CreateRoutes(&vpc.CreateRoutesRequest{
+		RouteTableId: common.StringPtr("rtb-test"),
+		Routes: []*vpc.Route{{
+			DestinationCidrBlock: common.StringPtr("172.16.4.0/24"),
+			GatewayType:          common.StringPtr(routeGatewayTypeNormalCVM),
+			GatewayId:            common.StringPtr("10.0.0.1"),
+			RouteDescription:     common.StringPtr(""),
+		}},
+	})
+	cloud.txConfig.ClusterCIDR = "172.16.0.0/16"
+	routes, err = cloud.ListRoutes(context.Background(), "kubernetes")
+	if err != nil || len(routes) != 3 || routes[2].DestinationCIDR != "172.16.4.0/24" {
+		t.Fatalf("ListRoutes adopting in --cluster-cidr = %+v, %v, want 3 routes", routes, err)
+	}
+}
+
+func TestTKERouteBackendScope(t *testing.T) {
+	cloud := newTestCloud(newFakeCLB(), newFakeCVM(newTestInstance("ins-1", "10.0.0.1")))
+	cloud.txConfig.ClusterRouteTable = "route-table"
+	fakeTke := &fakeTKE{}
+	cloud.tke = fakeTke
+	cloud.kubeClient = fake.NewSimpleClientset(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "10.0.0.1"},
+		Status:     v1.NodeStatus{Addresses: []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: "10.0.0.1"}}},
+	})
+	for cidr, gateway := range map[string]string{
+		"172.16.0.0/24": "10.0.0.1",
+		// a route of another cluster sharing the table
+		"172.17.0.0/24": "10.0.0.2",
+		// a route of a deleted node of the cluster
+		"172.16.1.0/24": "10.0.0.3",
+	} {
+		fakeTke.CreateClusterRoute(&tke.CreateClusterRouteRequest{
+			RouteTableName:       common.StringPtr("route-table"),
+			DestinationCidrBlock: common.StringPtr(cidr),
+			GatewayIp:            common.StringPtr(gateway),
+		})
+	}
+
+	// without --cluster-cidr the routes via the nodes are the cluster's
+	routes, err := cloud.routeBackend().ListRoutes(context.Background(), "kubernetes")
+	if err != nil || len(routes) != 1 || routes[0].DestinationCIDR != "172.16.0.0/24" {
+		t.Errorf("ListRoutes = %+v, %v, want the route via the node only", routes, err)
+	}
+	// with --cluster-cidr the routes inside it are, the routes of deleted nodes included
+	cloud.txConfig.ClusterCIDR = "172.16.0.0/16"
+	routes, err = cloud.routeBackend().ListRoutes(context.Background(), "kubernetes")
+	if err != nil || len(routes) != 2 {
+		t.Errorf("ListRoutes with --cluster-cidr = %+v, %v, want the 2 routes inside it", routes, err)
+	}
+	for _, route := range routes {
+		if route.DestinationCIDR == "172.17.0.0/24" {
+			t.Errorf("ListRoutes returned the route of another cluster %+v", route)
+		}
+	}
+}
+
+func TestInClusterCIDRs(t *testing.T) {
+	clusterCIDRs := parseClusterCIDRs("172.16.0.0/16, fd00::/48,invalid")
+	if len(clusterCIDRs) != 2 {
+		t.Fatalf("parseClusterCIDRs = %v, want 2 CIDRs", clusterCIDRs)
+	}
+	testCases := map[string]bool{
+		"172.16.3.0/24":   true,
+		"172.16.0.0/16":   true,
+		"172.0.0.0/8":     false,
+		"172.17.0.0/24":   false,
+		"fd00:0:0:1::/64": true,
+		"fd01::/64":       false,
+		"invalid":         false,
+	}
+	for cidr, expected := range testCases {
+		if actual := inClusterCIDRs(cidr, clusterCIDRs); actual != expected {
+			t.Errorf("inClusterCIDRs(%s) = %v, want %v", cidr, actual, expected)
+		}
+	}
+}
+
+func TestRouteConflicts(t *testing.T) {
+	cloud, fakeVpc := newTestVPCRouteCloud()
+	cloud.cvm = newFakeCVM(newTestInstance("ins-1", "10.0.0.1"), newTestInstance("ins-2", "10.0.0.2"))
+	// the conflict events are recorded on the nodes
+	cloud.kubeClient = fake.NewSimpleClientset(
+		&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "10.0.0.1"}},
+		&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "10.0.0.2"}},
+	)
+	recorder := record.NewFakeRecorder(10)
+	cloud.eventRecorder = recorder
+	if err := cloud.CreateRoute(context.Background(), "kubernetes", "hint", &cloudProvider.Route{TargetNode: "10.0.0.1", DestinationCIDR: "172.16.0.0/24"}); err != nil {
+		t.Fatalf("CreateRoute error: %v", err)
+	}
+	fakeVpc.CreateRoutes(&vpc.CreateRoutesRequest{
+		RouteTableId: common.StringPtr("rtb-test"),
+		Routes: []*vpc.Route{
+			{
+				DestinationCidrBlock: common.StringPtr("192.168.0.0/16"),
+				GatewayType:          common.StringPtr("NAT"),
+				GatewayId:            common.StringPtr("nat-test"),
+			},
+			{
+				DestinationCidrBlock: common.StringPtr("0.0.0.0/0"),
+				GatewayType:          common.StringPtr("NAT"),
+				GatewayId:            common.StringPtr("nat-test"),
+			},
+		},
+	})
+
+	testCases := []struct {
+		cidr     string
+		node     types.NodeName
+		conflict string
+	}{
+		{cidr: "10.0.1.0/26", conflict: "subnet subnet-1"},
+		{cidr: "10.0.128.0/24", conflict: "vpc vpc-test"},
+		{cidr: "172.16.0.0/23", conflict: "route 172.16.0.0/24 via 10.0.0.1"},
+		{cidr: "172.16.0.0/24", node: "10.0.0.2", conflict: "route 172.16.0.0/24 via 10.0.0.1"},
+		// broader routes, the default route included, are less specific and do not conflict
+		{cidr: "192.168.1.0/24"},
+		{cidr: "172.16.1.0/24"},
+		// the same route via the same node exists already
+		{cidr: "172.16.0.0/24"},
+	}
+	for _, testCase := range testCases {
+		if testCase.node == "" {
+			testCase.node = "10.0.0.1"
+		}
+		err := cloud.CreateRoute(context.Background(), "kubernetes", "hint", &cloudProvider.Route{TargetNode: testCase.node, DestinationCIDR: testCase.cidr})
+		var conflict *RouteCIDRConflictError
+		if testCase.conflict == "" {
+			if err != nil {
+				t.Errorf("%s: CreateRoute error: %v", testCase.cidr, err)
+			}
+			continue
+		}
+		if !errors.As(err, &conflict) || !strings.HasPrefix(conflict.Conflict, testCase.conflict) {
+			t.Errorf("%s: CreateRoute = %v, want a conflict with %s", testCase.cidr, err, testCase.conflict)
+			continue
+		}
+		if event := <-recorder.Events; !strings.Contains(event, RouteConflictEventReason) {
+			t.Errorf("%s: event = %q, want reason %s", testCase.cidr, event, RouteConflictEventReason)
+		}
+	}
+	if routes := fakeVpc.routes("rtb-test"); len(routes) != 5 {
+		t.Errorf("%d routes after the conflicting CreateRoutes, want 5", len(routes))
+	}
+	if calls := fakeVpc.calls["CreateRoutes"]; calls != 4 {
+		t.Errorf("CreateRoutes called %d times, want 4", calls)
+	}
+}
+
+func TestCheckClusterCIDR(t *testing.T) {
+	cloud, _ := newTestVPCRouteCloud()
+	for clusterCIDR, conflict := range map[string]bool{
+		"":                         false,
+		"172.16.0.0/16":            false,
+		"172.16.0.0/16,fd00::/108": false,
+		"10.0.0.0/8":               true,
+		"172.16.0.0/16,10.0.0.0/8": true,
+	} {
+		cloud.txConfig.ClusterCIDR = clusterCIDR
+		err := cloud.checkClusterCIDR(context.Background())
+		var conflictErr *RouteCIDRConflictError
+		if errors.As(err, &conflictErr) != conflict || (!conflict && err != nil) {
+			t.Errorf("%q: checkClusterCIDR = %v, want conflict %v", clusterCIDR, err, conflict)
+		}
+	}
+	cloud.txConfig.ClusterCIDR = "172.16.0.0"
+	if err := cloud.checkClusterCIDR(context.Background()); err == nil {
+		t.Errorf("checkClusterCIDR of an invalid CIDR = nil, want an error")
+	}
+}
+
+func TestRouteConflictCache(t *testing.T) {
+	cloud, fakeVpc := newTestVPCRouteCloud()
+	fakeClock := clock.NewFakeClock(time.Now())
+	cloud.clock = fakeClock
+	cloud.initCaches()
+	backend := cloud.routeBackend()
+	route := &cloudRoute{DestinationCIDR: "172.16.0.0/24", GatewayIP: "10.0.0.1"}
+
+	for i := 0; i < 2; i++ {
+		if _, err := cloud.checkRouteConflict(context.Background(), backend, route); err != nil {
+			t.Fatalf("checkRouteConflict error: %v", err)
+		}
+	}
+	for _, action := range []string{"DescribeVpcs", "DescribeSubnets", "DescribeRouteTables"} {
+		if calls := fakeVpc.callCount(action); calls != 1 {
+			t.Errorf("%s called %d times by two checks, want 1", action, calls)
+		}
+	}
+
+	// our own route changes invalidate the routes of the table, not the vpc CIDRs
+	if err := cloud.CreateRoute(context.Background(), "kubernetes", "hint", &cloudProvider.Route{TargetNode: "10.0.0.1", DestinationCIDR: "172.16.0.0/24"}); err != nil {
+		t.Fatalf("CreateRoute error: %v", err)
+	}
+	if exists, err := cloud.checkRouteConflict(context.Background(), backend, route); !exists || err != nil {
+		t.Errorf("checkRouteConflict of the created route = %v, %v, want true, nil", exists, err)
+	}
+	if calls := fakeVpc.callCount("DescribeRouteTables"); calls != 2 {
+		t.Errorf("DescribeRouteTables called %d times after CreateRoute, want 2", calls)
+	}
+	if calls := fakeVpc.callCount("DescribeVpcs"); calls != 1 {
+		t.Errorf("DescribeVpcs called %d times after CreateRoute, want 1", calls)
+	}
+
+	fakeClock.Step(vpcCIDRTTLTime + time.Second)
+	if _, err := cloud.checkRouteConflict(context.Background(), backend, route); err != nil {
+		t.Fatalf("checkRouteConflict error: %v", err)
+	}
+	if calls := fakeVpc.callCount("DescribeVpcs"); calls != 2 {
+		t.Errorf("DescribeVpcs called %d times after the ttl, want 2", calls)
+	}
+}
+
+func TestNewCloudClusterOptions(t *testing.T) {
+	config := `{"region": "ap-guangzhou", "vpc_id": "vpc-test", "clb_name_prefix": "test", "tag_key": "cluster", "cluster_route_table": "rt-test", "secret_id": "id", "secret_key": "key"}`
+	cloud, err := newCloud(strings.NewReader(config), ClusterOptions{ClusterName: "test", ClusterCIDR: "172.16.0.0/16"})
+	if err != nil {
+		t.Fatalf("newCloud error: %v", err)
+	}
+	if cloud.txConfig.ClusterName != "test" || cloud.txConfig.ClusterCIDR != "172.16.0.0/16" {
+		t.Errorf("cluster name %q, cluster cidr %q, want the options", cloud.txConfig.ClusterName, cloud.txConfig.ClusterCIDR)
+	}
+
+	// the cloud config wins
+	config = `{"region": "ap-guangzhou", "vpc_id": "vpc-test", "clb_name_prefix": "test", "tag_key": "cluster", "cluster_route_table": "rt-test", "secret_id": "id", "secret_key": "key", "cluster_name": "config"}`
+	if cloud, err = newCloud(strings.NewReader(config), ClusterOptions{ClusterName: "test"}); err != nil {
+		t.Fatalf("newCloud error: %v", err)
+	}
+	if cloud.txConfig.ClusterName != "config" {
+		t.Errorf("cluster name %q, want config", cloud.txConfig.ClusterName)
+	}
+}
+
+func TestCheckConfigRouteBackend(t *testing.T) {
+	config := TxCloudConfig{
+		Region:           "ap-guangzhou",
+		VpcId:            "vpc-test",
+		CLBNamePrefix:    "test",
+		TagKey:           "cluster",
+		SecretId:         "id",
+		SecretKey:        "key",
+		RouteBackend:     RouteBackendVPC,
+		NodeNameStrategy: NodeNameStrategyPrivateIP,
+	}
+	if err := checkConfig(config); err == nil {
+		t.Errorf("expected the vpc route backend without RouteTableId to be rejected")
+	}
+	config.RouteTableId = "rtb-test"
+	if err := checkConfig(config); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	config.RouteBackend = "bgp"
+	if err := checkConfig(config); err == nil {
+		t.Errorf("expected unsupported route backend to be rejected")
+	}
+}
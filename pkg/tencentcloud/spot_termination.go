@@ -0,0 +1,200 @@
+package tencentcloud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog"
+)
+
+const (
+	// SpotTerminationTaintKey taints the nodes of spot instances scheduled for reclamation
+	SpotTerminationTaintKey = "tencentcloud.com/spot-termination"
+	// SpotTerminationTimeAnnotation is the termination time of the spot instance of the node, written by a node-local agent
+	SpotTerminationTimeAnnotation = "tencentcloud.com/spot-termination-time"
+	// SpotTerminationEventReason is the reason of the Event emitted on a termination notice
+	SpotTerminationEventReason = "SpotInstanceTerminationNotice"
+
+	// SpotTerminationSourceMetadata reads the notices from the metadata service of the instance we are running on
+	SpotTerminationSourceMetadata = "metadata"
+	// SpotTerminationSourceNodeAnnotation reads the notices from the SpotTerminationTimeAnnotation of the nodes
+	SpotTerminationSourceNodeAnnotation = "node-annotation"
+
+	instanceChargeTypeSpotPaid = "SPOTPAID"
+
+	defaultSpotTerminationInterval = 5 * time.Second
+	// spotTerminationTimeLayout is the layout of the metadata termination time, e.g. 2018-08-18 12:05:33
+	spotTerminationTimeLayout = "2006-01-02 15:04:05"
+)
+
+// SpotTerminationConfig configures the handling of spot instance termination notices
+type SpotTerminationConfig struct {
+	// Enabled starts the polling of termination notices
+	Enabled bool `json:"enabled"`
+	// Interval is the interval between two polls, in seconds
+	Interval int `json:"interval"`
+	// TaintEffect is the effect of the termination taint, NoSchedule or NoExecute, defaults to NoSchedule
+	TaintEffect string `json:"taint_effect"`
+	// Source is where the notices are read from, metadata or node-annotation, defaults to metadata
+	Source string `json:"source"`
+}
+
+// SpotTerminationNoticeSource tells when a spot instance is reclaimed
+type SpotTerminationNoticeSource interface {
+	// TerminationTime returns the time the instance of node is reclaimed at, ok is false when there is no notice
+	TerminationTime(ctx context.Context, node *v1.Node, instanceID string) (terminationTime time.Time, ok bool, err error)
+}
+
+// metadataSpotTerminationSource reads termination notices from the metadata service, which only knows
+// the instance we are running on, notices of other instances are never reported
+type metadataSpotTerminationSource struct {
+	metadata *MetadataClient
+}
+
+// TerminationTime returns the spot/termination-time of the metadata when instanceID is the instance we are running on
+func (s *metadataSpotTerminationSource) TerminationTime(ctx context.Context, node *v1.Node, instanceID string) (time.Time, bool, error) {
+	localInstanceID, err := s.metadata.InstanceID(ctx)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	if localInstanceID != instanceID {
+		return time.Time{}, false, nil
+	}
+	value, err := s.metadata.Get(ctx, metadataPathSpotTerminationTime)
+	if errors.Is(err, errMetadataNotFound) {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	terminationTime, err := parseSpotTerminationTime(value)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return terminationTime, true, nil
+}
+
+// nodeAnnotationSpotTerminationSource reads termination notices from the SpotTerminationTimeAnnotation of the nodes.
+// The metadata service only tells an instance about itself, so a node-local agent copies the spot/termination-time
+// of its instance to the annotation of its node, see examples/spot-termination-agent.yaml.
+type nodeAnnotationSpotTerminationSource struct{}
+
+// TerminationTime returns the termination time of the instance of node written to the node annotation
+func (nodeAnnotationSpotTerminationSource) TerminationTime(ctx context.Context, node *v1.Node, instanceID string) (time.Time, bool, error) {
+	value := node.Annotations[SpotTerminationTimeAnnotation]
+	if value == "" {
+		return time.Time{}, false, nil
+	}
+	terminationTime, err := parseSpotTerminationTime(value)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return terminationTime, true, nil
+}
+
+// parseSpotTerminationTime parses the termination time in RFC3339 or in the metadata layout, local time
+func parseSpotTerminationTime(value string) (time.Time, error) {
+	if terminationTime, err := time.Parse(time.RFC3339, value); err == nil {
+		return terminationTime, nil
+	}
+	terminationTime, err := time.ParseInLocation(spotTerminationTimeLayout, value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid spot termination time %q: %v", value, err)
+	}
+	return terminationTime, nil
+}
+
+// newSpotTerminationSource returns the notice source configured by SpotTermination.Source
+func (cloud *Cloud) newSpotTerminationSource() SpotTerminationNoticeSource {
+	if cloud.txConfig.SpotTermination.Source == SpotTerminationSourceNodeAnnotation {
+		return nodeAnnotationSpotTerminationSource{}
+	}
+	return &metadataSpotTerminationSource{metadata: cloud.metadata}
+}
+
+// runSpotTermination handles spot termination notices every interval until stop is closed
+func (cloud *Cloud) runSpotTermination(interval time.Duration, stop <-chan struct{}) {
+	klog.V(3).Infof("tencentcloud.runSpotTermination: poll every %v\n", interval)
+	if !cloud.waitForNodeLister(stop) {
+		return
+	}
+	wait.Until(func() {
+		if err := cloud.handleSpotTerminationNotices(context.TODO()); err != nil {
+			klog.Warningf("tencentcloud.runSpotTermination: handle error: %v\n", err)
+		}
+	}, interval, stop)
+}
+
+// handleSpotTerminationNotices taints and cordons the nodes of spot instances with a termination notice
+func (cloud *Cloud) handleSpotTerminationNotices(ctx context.Context) error {
+	klog.V(3).Infof("tencentcloud.handleSpotTerminationNotices(): entered\n")
+	nodes, err := cloud.listNodes(ctx)
+	if err != nil {
+		klog.Warningf("tencentcloud.handleSpotTerminationNotices: list nodes error: %v\n", err)
+		return err
+	}
+
+	for _, node := range nodes {
+		if hasTaint(node, SpotTerminationTaintKey) {
+			continue
+		}
+		instance, err := cloud.getInstanceByNode(ctx, node)
+		if err != nil {
+			klog.Warningf("tencentcloud.handleSpotTerminationNotices: node %s Get error: %v\n", node.Name, err)
+			continue
+		}
+		if instance.InstanceChargeType == nil || *instance.InstanceChargeType != instanceChargeTypeSpotPaid {
+			continue
+		}
+
+		terminationTime, ok, err := cloud.spotTerminationSource.TerminationTime(ctx, node, *instance.InstanceId)
+		if err != nil {
+			klog.Warningf("tencentcloud.handleSpotTerminationNotices: instance %s notice error: %v\n", *instance.InstanceId, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if err := cloud.taintSpotTerminationNode(ctx, node); err != nil {
+			klog.Warningf("tencentcloud.handleSpotTerminationNotices: node %s patch error: %v\n", node.Name, err)
+			continue
+		}
+		cloud.eventRecorder.Eventf(node, v1.EventTypeWarning, SpotTerminationEventReason,
+			"Spot instance %s will be reclaimed at %s, node cordoned", *instance.InstanceId, terminationTime.Format(time.RFC3339))
+		klog.Infof("tencentcloud.handleSpotTerminationNotices: node %s cordoned, instance %s reclaimed at %v\n", node.Name, *instance.InstanceId, terminationTime)
+	}
+	return nil
+}
+
+// taintSpotTerminationNode adds the termination taint to node and marks it unschedulable
+func (cloud *Cloud) taintSpotTerminationNode(ctx context.Context, node *v1.Node) error {
+	effect := v1.TaintEffect(cloud.txConfig.SpotTermination.TaintEffect)
+	if effect == "" {
+		effect = v1.TaintEffectNoSchedule
+	}
+	timeAdded := metav1.Now()
+
+	updated := node.DeepCopy()
+	updated.Spec.Unschedulable = true
+	updated.Spec.Taints = append(updated.Spec.Taints, v1.Taint{
+		Key:       SpotTerminationTaintKey,
+		Effect:    effect,
+		TimeAdded: &timeAdded,
+	})
+	return cloud.patchNode(ctx, node, updated)
+}
+
+// hasTaint check the node has a taint with key
+func hasTaint(node *v1.Node, key string) bool {
+	for _, taint := range node.Spec.Taints {
+		if taint.Key == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,185 @@
+package tencentcloud
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestHandleSpotTerminationNotices(t *testing.T) {
+	spot := newTestInstance("ins-1", "10.0.0.1")
+	spot.InstanceChargeType = common.StringPtr(instanceChargeTypeSpotPaid)
+	otherSpot := newTestInstance("ins-2", "10.0.0.2")
+	otherSpot.InstanceChargeType = common.StringPtr(instanceChargeTypeSpotPaid)
+	onDemand := newTestInstance("ins-3", "10.0.0.3")
+
+	testCases := []struct {
+		name string
+		// terminationTimes are the termination time annotations by node name
+		terminationTimes map[string]string
+		tainted          []string
+	}{
+		{
+			name:             "notices",
+			terminationTimes: map[string]string{"10.0.0.1": "2018-08-18 12:05:33", "10.0.0.2": "2018-08-18T12:05:33+08:00"},
+			tainted:          []string{"10.0.0.1", "10.0.0.2"},
+		},
+		{name: "no notice"},
+		{name: "invalid notice", terminationTimes: map[string]string{"10.0.0.1": "soon"}},
+		{name: "notice of a pay-as-you-go instance", terminationTimes: map[string]string{"10.0.0.3": "2018-08-18 12:05:33"}},
+	}
+	for _, testCase := range testCases {
+		recorder := record.NewFakeRecorder(10)
+		cloud := newTestCloud(newFakeCLB(), newFakeCVM(spot, otherSpot, onDemand))
+		cloud.spotTerminationSource = nodeAnnotationSpotTerminationSource{}
+		cloud.eventRecorder = recorder
+		var objects []runtime.Object
+		for _, name := range []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"} {
+			node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}
+			if terminationTime, ok := testCase.terminationTimes[name]; ok {
+				node.Annotations = map[string]string{SpotTerminationTimeAnnotation: terminationTime}
+			}
+			if name == "10.0.0.2" {
+				// taints of others are kept by the patch
+				node.Spec.Taints = []v1.Taint{{Key: "dedicated", Value: "gpu", Effect: v1.TaintEffectNoSchedule}}
+			}
+			objects = append(objects, node)
+		}
+		cloud.kubeClient = fake.NewSimpleClientset(objects...)
+
+		if err := cloud.handleSpotTerminationNotices(context.Background()); err != nil {
+			t.Fatalf("%s: unexpected error: %v", testCase.name, err)
+		}
+		// a second poll must not taint nor emit twice
+		if err := cloud.handleSpotTerminationNotices(context.Background()); err != nil {
+			t.Fatalf("%s: unexpected error: %v", testCase.name, err)
+		}
+
+		nodes, err := cloud.kubeClient.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", testCase.name, err)
+		}
+		var tainted []string
+		for _, node := range nodes.Items {
+			if !hasTaint(&node, SpotTerminationTaintKey) {
+				continue
+			}
+			wantTaints := 1
+			if node.Name == "10.0.0.2" {
+				wantTaints = 2
+			}
+			if !node.Spec.Unschedulable || len(node.Spec.Taints) != wantTaints || node.Spec.Taints[wantTaints-1].Effect != v1.TaintEffectNoSchedule {
+				t.Errorf("%s: node %s spec = %+v, want unschedulable with the NoSchedule taint added", testCase.name, node.Name, node.Spec)
+			}
+			tainted = append(tainted, node.Name)
+		}
+		if strings.Join(tainted, ",") != strings.Join(testCase.tainted, ",") {
+			t.Errorf("%s: tainted nodes = %v, want %v", testCase.name, tainted, testCase.tainted)
+		}
+		if len(recorder.Events) != len(testCase.tainted) {
+			t.Errorf("%s: %d events, want %d", testCase.name, len(recorder.Events), len(testCase.tainted))
+		}
+		for range testCase.tainted {
+			if event := <-recorder.Events; !strings.Contains(event, SpotTerminationEventReason) {
+				t.Errorf("%s: event = %q, want reason %s", testCase.name, event, SpotTerminationEventReason)
+			}
+		}
+	}
+}
+
+func TestMetadataSpotTerminationSource(t *testing.T) {
+	testCases := []struct {
+		name       string
+		values     map[string]string
+		instanceID string
+		expectedOK bool
+		expectErr  bool
+	}{
+		{
+			name: "notice",
+			values: map[string]string{
+				"/latest/meta-data/instance-id":           "ins-1",
+				"/latest/meta-data/spot/termination-time": "2018-08-18 12:05:33",
+			},
+			instanceID: "ins-1",
+			expectedOK: true,
+		},
+		{
+			name:       "no notice",
+			values:     map[string]string{"/latest/meta-data/instance-id": "ins-1"},
+			instanceID: "ins-1",
+		},
+		{
+			name: "notice of another instance",
+			values: map[string]string{
+				"/latest/meta-data/instance-id":           "ins-1",
+				"/latest/meta-data/spot/termination-time": "2018-08-18 12:05:33",
+			},
+			instanceID: "ins-2",
+		},
+		{
+			name: "invalid notice",
+			values: map[string]string{
+				"/latest/meta-data/instance-id":           "ins-1",
+				"/latest/meta-data/spot/termination-time": "soon",
+			},
+			instanceID: "ins-1",
+			expectErr:  true,
+		},
+		{name: "not on a CVM", instanceID: "ins-1", expectErr: true},
+	}
+	for _, testCase := range testCases {
+		server := newFakeMetadataServer(testCase.values)
+		source := &metadataSpotTerminationSource{metadata: NewMetadataClient(server.URL+"/latest/meta-data/", time.Second)}
+		node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "10.0.0.1"}}
+
+		terminationTime, ok, err := source.TerminationTime(context.Background(), node, testCase.instanceID)
+		server.Close()
+		if testCase.expectErr {
+			if err == nil {
+				t.Errorf("%s: expected an error", testCase.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", testCase.name, err)
+		}
+		if ok != testCase.expectedOK {
+			t.Errorf("%s: ok = %v, want %v", testCase.name, ok, testCase.expectedOK)
+		}
+		if ok && terminationTime.Format(spotTerminationTimeLayout) != "2018-08-18 12:05:33" {
+			t.Errorf("%s: termination time = %v, want 2018-08-18 12:05:33", testCase.name, terminationTime)
+		}
+	}
+}
+
+func TestNewSpotTerminationSource(t *testing.T) {
+	cloud := newTestCloud(newFakeCLB(), newFakeCVM())
+	cloud.txConfig.SpotTermination.Source = SpotTerminationSourceMetadata
+	if _, ok := cloud.newSpotTerminationSource().(*metadataSpotTerminationSource); !ok {
+		t.Errorf("source %s: got %T, want *metadataSpotTerminationSource", SpotTerminationSourceMetadata, cloud.newSpotTerminationSource())
+	}
+	cloud.txConfig.SpotTermination.Source = SpotTerminationSourceNodeAnnotation
+	if _, ok := cloud.newSpotTerminationSource().(nodeAnnotationSpotTerminationSource); !ok {
+		t.Errorf("source %s: got %T, want nodeAnnotationSpotTerminationSource", SpotTerminationSourceNodeAnnotation, cloud.newSpotTerminationSource())
+	}
+}
+
+func TestParseSpotTerminationTime(t *testing.T) {
+	for _, value := range []string{"2018-08-18 12:05:33", "2018-08-18T12:05:33+08:00"} {
+		if _, err := parseSpotTerminationTime(value); err != nil {
+			t.Errorf("parseSpotTerminationTime(%q) error: %v", value, err)
+		}
+	}
+	if _, err := parseSpotTerminationTime("soon"); err == nil {
+		t.Errorf("parseSpotTerminationTime(%q) error = nil, want an error", "soon")
+	}
+}
@@ -0,0 +1,146 @@
+package tencentcloud
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	clb "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/clb/v20180317"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog"
+)
+
+const (
+	// task status returned by DescribeTaskStatus. 0：成功，1：失败，2：进行中。
+	taskStatusSucceeded int64 = 0
+	taskStatusFailed    int64 = 1
+	taskStatusRunning   int64 = 2
+
+	defaultTaskWaitInitialInterval = 500 * time.Millisecond
+	defaultTaskWaitMaxInterval     = 5 * time.Second
+	defaultTaskWaitTimeout         = 60 * time.Second
+)
+
+// TaskFailedError is returned when Tencent Cloud reports an async task as failed
+type TaskFailedError struct {
+	TaskId string
+}
+
+func (e *TaskFailedError) Error() string {
+	return fmt.Sprintf("task %s executed failed", e.TaskId)
+}
+
+// taskStatusDescriber is the part of the clb client the TaskWaiter depends on
+type taskStatusDescriber interface {
+	DescribeTaskStatus(request *clb.DescribeTaskStatusRequest) (*clb.DescribeTaskStatusResponse, error)
+}
+
+// TaskWaiter polls Tencent Cloud async api tasks until they are done
+type TaskWaiter struct {
+	client taskStatusDescriber
+	// Backoff controls the interval between two polls, Steps is ignored
+	Backoff wait.Backoff
+	// Timeout bounds the wait for a single task, 0 means only ctx bounds it
+	Timeout time.Duration
+}
+
+// NewTaskWaiter creates a TaskWaiter polling with exponential backoff from initialInterval up to maxInterval
+func NewTaskWaiter(client taskStatusDescriber, initialInterval, maxInterval, timeout time.Duration) *TaskWaiter {
+	if initialInterval <= 0 {
+		initialInterval = defaultTaskWaitInitialInterval
+	}
+	if maxInterval < initialInterval {
+		maxInterval = initialInterval
+	}
+	return &TaskWaiter{
+		client: client,
+		Backoff: wait.Backoff{
+			Duration: initialInterval,
+			Factor:   2,
+			Jitter:   0.1,
+			Cap:      maxInterval,
+		},
+		Timeout: timeout,
+	}
+}
+
+// Wait waits the task done, returns *TaskFailedError if the task failed
+func (w *TaskWaiter) Wait(ctx context.Context, taskId string) error {
+	klog.V(3).Infof("tencentcloud.TaskWaiter.Wait(\"%s\"): entered\n", taskId)
+	if w.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, w.Timeout)
+		defer cancel()
+	}
+
+	backoff := w.Backoff
+	backoff.Steps = int(^uint(0) >> 1)
+	for i := 0; ; i++ {
+		status, err := w.getTaskStatus(ctx, taskId)
+		if err != nil {
+			klog.V(3).Infof("tencentcloud.TaskWaiter.Wait: return: %v\n", err)
+			return err
+		}
+		switch status {
+		case taskStatusSucceeded:
+			klog.V(2).Infof("tencentcloud.TaskWaiter.Wait: Task %s executed successfully.\n", taskId)
+			return nil
+		case taskStatusFailed:
+			klog.Warningf("tencentcloud.TaskWaiter.Wait: Task %s executed failed!\n", taskId)
+			return &TaskFailedError{TaskId: taskId}
+		case taskStatusRunning:
+			klog.V(2).Infof("tencentcloud.TaskWaiter.Wait: Task %s executing, Current number: %d, Try again next time.\n", taskId, i)
+		default:
+			klog.Warningf("tencentcloud.TaskWaiter.Wait: Task %s executed return a not expected value: %d\n", taskId, status)
+			return fmt.Errorf("task %s executed return a not expected value: %d", taskId, status)
+		}
+
+		timer := time.NewTimer(backoff.Step())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			klog.Warningf("tencentcloud.TaskWaiter.Wait: task %s wait aborted: %v\n", taskId, ctx.Err())
+			return fmt.Errorf("task %s wait aborted: %w", taskId, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// WaitAll waits many tasks done concurrently, returns the first error in tasks order
+func (w *TaskWaiter) WaitAll(ctx context.Context, taskIds []string) error {
+	klog.V(3).Infof("tencentcloud.TaskWaiter.WaitAll(%v): entered\n", taskIds)
+	errs := make([]error, len(taskIds))
+	var wg sync.WaitGroup
+	for idx, taskId := range taskIds {
+		wg.Add(1)
+		go func(idx int, taskId string) {
+			defer wg.Done()
+			errs[idx] = w.Wait(ctx, taskId)
+		}(idx, taskId)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getTaskStatus return Tencent Cloud async api task status
+func (w *TaskWaiter) getTaskStatus(ctx context.Context, taskId string) (int64, error) {
+	request := clb.NewDescribeTaskStatusRequest()
+	request.TaskId = common.StringPtr(taskId)
+	request.SetContext(ctx)
+	response, err := w.client.DescribeTaskStatus(request)
+	if err != nil {
+		klog.Warningf("tencentcloud.TaskWaiter.getTaskStatus: Get error: %s\n", err)
+		return 0, err
+	}
+
+	klog.V(3).Infof("tencentcloud.TaskWaiter.getTaskStatus: return(taskId:%s): %d\n", taskId, *response.Response.Status)
+	return *response.Response.Status, nil
+}
@@ -0,0 +1,116 @@
+package tencentcloud
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	clb "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/clb/v20180317"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+)
+
+// fakeTaskStatus returns the queued statuses of a task one by one, repeating the last one
+type fakeTaskStatus struct {
+	mu       sync.Mutex
+	statuses map[string][]int64
+	calls    map[string]int
+	// lastContext is the context of the last request
+	lastContext context.Context
+}
+
+func newFakeTaskStatus(statuses map[string][]int64) *fakeTaskStatus {
+	return &fakeTaskStatus{statuses: statuses, calls: make(map[string]int)}
+}
+
+func (f *fakeTaskStatus) DescribeTaskStatus(request *clb.DescribeTaskStatusRequest) (*clb.DescribeTaskStatusResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	taskId := *request.TaskId
+	f.lastContext = request.GetContext()
+	queue, ok := f.statuses[taskId]
+	if !ok {
+		return nil, errors.New("unknown task " + taskId)
+	}
+	idx := f.calls[taskId]
+	if idx >= len(queue) {
+		idx = len(queue) - 1
+	}
+	f.calls[taskId]++
+
+	response := clb.NewDescribeTaskStatusResponse()
+	response.Response = &struct {
+		Status          *int64    `json:"Status,omitempty" name:"Status"`
+		LoadBalancerIds []*string `json:"LoadBalancerIds,omitempty" name:"LoadBalancerIds"`
+		RequestId       *string   `json:"RequestId,omitempty" name:"RequestId"`
+	}{Status: common.Int64Ptr(queue[idx])}
+	return response, nil
+}
+
+func TestTaskWaiterWait(t *testing.T) {
+	fake := newFakeTaskStatus(map[string][]int64{
+		"ok":      {taskStatusRunning, taskStatusRunning, taskStatusSucceeded},
+		"failed":  {taskStatusRunning, taskStatusFailed},
+		"unknown": {9},
+	})
+	waiter := NewTaskWaiter(fake, time.Millisecond, 2*time.Millisecond, time.Second)
+
+	if err := waiter.Wait(context.Background(), "ok"); err != nil {
+		t.Errorf("expected task ok to succeed, got %v", err)
+	}
+	if fake.calls["ok"] != 3 {
+		t.Errorf("expected 3 polls, got %d", fake.calls["ok"])
+	}
+
+	err := waiter.Wait(context.Background(), "failed")
+	var failed *TaskFailedError
+	if !errors.As(err, &failed) || failed.TaskId != "failed" {
+		t.Errorf("expected TaskFailedError, got %v", err)
+	}
+
+	if err := waiter.Wait(context.Background(), "unknown"); err == nil {
+		t.Errorf("expected an error for unexpected status")
+	}
+}
+
+func TestTaskWaiterWaitContextCanceled(t *testing.T) {
+	fake := newFakeTaskStatus(map[string][]int64{"running": {taskStatusRunning}})
+	waiter := NewTaskWaiter(fake, time.Millisecond, time.Millisecond, 0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := waiter.Wait(ctx, "running"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context deadline exceeded, got %v", err)
+	}
+}
+
+func TestTaskWaiterWaitContextPassed(t *testing.T) {
+	fake := newFakeTaskStatus(map[string][]int64{"ok": {taskStatusSucceeded}})
+	waiter := NewTaskWaiter(fake, time.Millisecond, time.Millisecond, time.Second)
+
+	// the polls carry the wait deadline, the api client gives up its rate limit wait and retries with it
+	if err := waiter.Wait(context.Background(), "ok"); err != nil {
+		t.Fatalf("expected task ok to succeed, got %v", err)
+	}
+	if _, ok := fake.lastContext.Deadline(); !ok {
+		t.Errorf("expected the poll context to have the wait deadline")
+	}
+}
+
+func TestTaskWaiterWaitAll(t *testing.T) {
+	fake := newFakeTaskStatus(map[string][]int64{
+		"a": {taskStatusRunning, taskStatusSucceeded},
+		"b": {taskStatusSucceeded},
+		"c": {taskStatusRunning, taskStatusFailed},
+	})
+	waiter := NewTaskWaiter(fake, time.Millisecond, time.Millisecond, time.Second)
+
+	if err := waiter.WaitAll(context.Background(), []string{"a", "b"}); err != nil {
+		t.Errorf("expected all tasks to succeed, got %v", err)
+	}
+	var failed *TaskFailedError
+	if err := waiter.WaitAll(context.Background(), []string{"a", "b", "c"}); !errors.As(err, &failed) || failed.TaskId != "c" {
+		t.Errorf("expected TaskFailedError for c, got %v", err)
+	}
+}
@@ -2,10 +2,12 @@ package tencentcloud
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
-	"sort"
+	"fmt"
 	"strconv"
-	"time"
+	"strings"
 
 	clb "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/clb/v20180317"
 	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
@@ -42,6 +44,9 @@ const (
 
 	nodeLabelKeyOfLoadBalancerDefault   = "kubernetes.io/role"
 	nodeLabelValueOfLoadBalancerDefault = "node"
+
+	// loadBalancerClientTokenMaxRenewals bounds the renewals of the client token of the CLBs deleted since they were created with it
+	loadBalancerClientTokenMaxRenewals = 10
 )
 
 var (
@@ -74,18 +79,19 @@ func (cloud *Cloud) getLoadBalancerName(ctx context.Context, clusterName string,
 }
 
 // getLoadBalancerListeners return Tencent Cloud LoadBalancer Listeners Name for LoadBalancer
-func (cloud *Cloud) getLoadBalancerListeners(LoadBalancerId string) ([]*clb.Listener, error) {
+func (cloud *Cloud) getLoadBalancerListeners(ctx context.Context, LoadBalancerId string) ([]*clb.Listener, error) {
 	klog.V(3).Infof("tencentcloud.getLoadBalancerListeners(\"%s\"): entered\n", LoadBalancerId)
 
 	cacheKey := cacheNamePreCLBListener + LoadBalancerId
-	cacheValue, exist := cloud.cache.Get(cacheKey)
+	cacheValue, exist := cloud.listenerCache.Get(cacheKey)
 	if exist {
-		klog.V(3).Infof("tencentcloud.getLoadBalancerListeners: cache return(CLB_ID:%s):  %T, nil\n", LoadBalancerId, cacheValue.([]*clb.Listener))
-		return cacheValue.([]*clb.Listener), nil
+		klog.V(3).Infof("tencentcloud.getLoadBalancerListeners: cache return(CLB_ID:%s):  %T, nil\n", LoadBalancerId, cacheValue)
+		return cacheValue, nil
 	}
 
 	request := clb.NewDescribeListenersRequest()
 	request.LoadBalancerId = common.StringPtr(LoadBalancerId)
+	request.SetContext(ctx)
 	response, err := cloud.clb.DescribeListeners(request)
 	if _, ok := err.(*cloudErrors.TencentCloudSDKError); ok {
 		klog.Warningf("tencentcloud.getLoadBalancerListeners: Get TencentCloud error: %s\n", err)
@@ -98,26 +104,27 @@ func (cloud *Cloud) getLoadBalancerListeners(LoadBalancerId string) ([]*clb.List
 		return nil, err
 	}
 
-	cloud.cache.Set(cacheKey, response.Response.Listeners)
+	cloud.listenerCache.Set(cacheKey, response.Response.Listeners)
 	klog.V(3).Infof("tencentcloud.getLoadBalancerListeners: return(lbID:%s): %+v, nil\n", LoadBalancerId, response.Response.Listeners)
 	return response.Response.Listeners, nil
 }
 
 // getLoadBalancer return Tencent Cloud LoadBalancer for LoadBalancer name
-func (cloud *Cloud) getLoadBalancer(name string, service *v1.Service) (*clb.LoadBalancer, error) {
+func (cloud *Cloud) getLoadBalancer(ctx context.Context, name string, service *v1.Service) (*clb.LoadBalancer, error) {
 	klog.V(3).Infof("tencentcloud.getLoadBalancerByName(\"%s\"): entered\n", name)
 
 	cacheKey := cacheNamePreCLB + name
-	cacheValue, exist := cloud.cache.Get(cacheKey)
+	cacheValue, exist := cloud.loadBalancerCache.Get(cacheKey)
 	if exist {
-		klog.V(3).Infof("tencentcloud.getLoadBalancerByName: cache return(name:%s): %T, nil\n", name, cacheValue.(*clb.LoadBalancer))
-		return cacheValue.(*clb.LoadBalancer), nil
+		klog.V(3).Infof("tencentcloud.getLoadBalancerByName: cache return(name:%s): %T, nil\n", name, cacheValue)
+		return cacheValue, nil
 	}
 
 	// we don't need to check loadbalancer kind here because ensureLoadBalancerInstance will ensure the kind is right
 	request := clb.NewDescribeLoadBalancersRequest()
 	request.LoadBalancerName = common.StringPtr(name)
 	request.Filters = cloud.getLoadBalancerFilter(service)
+	request.SetContext(ctx)
 
 	response, err := cloud.clb.DescribeLoadBalancers(request)
 	if _, ok := err.(*cloudErrors.TencentCloudSDKError); ok {
@@ -133,15 +140,29 @@ func (cloud *Cloud) getLoadBalancer(name string, service *v1.Service) (*clb.Load
 	count := len(response.Response.LoadBalancerSet)
 	switch {
 	case count == 1:
-		cloud.cache.Set(cacheKey, response.Response.LoadBalancerSet[0])
+		cloud.loadBalancerCache.Set(cacheKey, response.Response.LoadBalancerSet[0])
 		klog.V(3).Infof("tencentcloud.getLoadBalancerByName: return(name: %s, CLB ID: %s): %T nil\n", *response.Response.LoadBalancerSet[0].LoadBalancerName, *response.Response.LoadBalancerSet[0].LoadBalancerId, response.Response.LoadBalancerSet[0])
 		return response.Response.LoadBalancerSet[0], nil
 	case count < 1:
 		klog.Warningf("tencentcloud.getLoadBalancerByName: return(name: %s): nil, %v\n", name, ErrCloudLoadBalancerNotFound)
 		return nil, ErrCloudLoadBalancerNotFound
 	default:
-		klog.Warningf("tencentcloud.getLoadBalancerByName: find CLB count > 1,count: %d. return(name: %s): nil, %v\n", count, name, ErrCloudLoadBalancerNotFound)
-		return nil, ErrCloudLoadBalancerNotFound
+		klog.Warningf("tencentcloud.getLoadBalancerByName: find CLB count > 1,count: %d. return(name: %s): nil, %v\n", count, name, ErrCloudLoadBalancerDuplicated)
+		return nil, ErrCloudLoadBalancerDuplicated
+	}
+}
+
+// invalidateLoadBalancerCache evict the cached CLB after it is created or deleted
+func (cloud *Cloud) invalidateLoadBalancerCache(name string) {
+	if cacheKey := cacheNamePreCLB + name; cloud.loadBalancerCache.Delete(cacheKey) {
+		klog.V(3).Infof("tencentcloud.invalidateLoadBalancerCache: delete cache done. key: %s\n", cacheKey)
+	}
+}
+
+// invalidateLoadBalancerListenersCache evict the cached listeners after any of them is created, modified or deleted
+func (cloud *Cloud) invalidateLoadBalancerListenersCache(loadBalancerId string) {
+	if cacheKey := cacheNamePreCLBListener + loadBalancerId; cloud.listenerCache.Delete(cacheKey) {
+		klog.V(3).Infof("tencentcloud.invalidateLoadBalancerListenersCache: delete cache done. key: %s\n", cacheKey)
 	}
 }
 
@@ -163,7 +184,7 @@ func (cloud *Cloud) getLoadBalancerFilter(service *v1.Service) []*clb.Filter {
 func (cloud *Cloud) ensureLoadBalancerInstance(ctx context.Context, clusterName string, service *v1.Service) error {
 	klog.V(3).Infof("tencentcloud.ensureLoadBalancerInstance(\"%s %T\"): entered\n", clusterName, service)
 	loadBalancerName := cloud.getLoadBalancerName(ctx, clusterName, service)
-	loadBalancer, err := cloud.getLoadBalancer(loadBalancerName, service)
+	loadBalancer, err := cloud.getLoadBalancer(ctx, loadBalancerName, service)
 
 	if err != nil {
 		if err != ErrCloudLoadBalancerNotFound {
@@ -234,157 +255,162 @@ func (cloud *Cloud) ensureLoadBalancerListeners(ctx context.Context, clusterName
 	klog.V(3).Infof("tencentcloud.ensureLoadBalancerListeners(\"%s, %T\"): entered\n", clusterName, service)
 
 	loadBalancerName := cloud.getLoadBalancerName(ctx, clusterName, service)
-	loadBalancer, err := cloud.getLoadBalancer(loadBalancerName, service)
+	loadBalancer, err := cloud.getLoadBalancer(ctx, loadBalancerName, service)
 	if err != nil {
 		klog.Warningf("tencentcloud.ensureLoadBalancerListeners: Get error: %s\n", err)
 		klog.V(3).Infof("tencentcloud.ensureLoadBalancerListeners: return: %v\n", err)
 		return err
 	}
 
-	loadBalancerListeners, err := cloud.getLoadBalancerListeners(*loadBalancer.LoadBalancerId)
+	loadBalancerListeners, err := cloud.getLoadBalancerListeners(ctx, *loadBalancer.LoadBalancerId)
 	if err != nil {
 		klog.Warningf("tencentcloud.ensureLoadBalancerListeners: Get error: %s\n", err)
 		klog.V(3).Infof("tencentcloud.ensureLoadBalancerListeners: return: %v\n", err)
 		return err
 	}
 
-	usedListenerIds := make([]string, 0)
-	createdServicePortNames := make([]string, 0)
-	findOneListenerValid := func(port v1.ServicePort) (listenerId string) {
-		listenerId = ""
-		for _, listener := range loadBalancerListeners {
-			if *listener.Port == int64(port.Port) && *listener.Protocol == string(port.Protocol) {
-				klog.V(3).Infof("tencentcloud.ensureLoadBalancerListeners: return: %s\n", *listener.ListenerId)
-				return *listener.ListenerId
-			}
-		}
-		return
+	listenersToCreate, listenersToModify, listenersToDelete := diffLoadBalancerListeners(service.Spec.Ports, loadBalancerListeners)
+	if len(listenersToCreate) > 0 || len(listenersToModify) > 0 || len(listenersToDelete) > 0 {
+		// the cached listeners are stale whether or not all the changes below succeed
+		defer cloud.invalidateLoadBalancerListenersCache(*loadBalancer.LoadBalancerId)
 	}
 
-	for _, port := range service.Spec.Ports {
-		listenerId := findOneListenerValid(port)
-		if listenerId != "" {
-			// TODO check if port name is unique
-			createdServicePortNames = append(createdServicePortNames, port.Name)
-			usedListenerIds = append(usedListenerIds, listenerId)
+	// delete first, a port may be reused by the service under a different protocol
+	if len(listenersToDelete) > 0 {
+		listenerIds := make([]string, len(listenersToDelete))
+		for idx, listener := range listenersToDelete {
+			listenerIds[idx] = *listener.ListenerId
 		}
-	}
-
-	sort.Strings(usedListenerIds)
-	sort.Strings(createdServicePortNames)
-	listenersToCreate := make([]*clb.CreateListenerRequest, 0)
-	for _, port := range service.Spec.Ports {
-		ensured := false
-		if isExist(port.Name, createdServicePortNames) {
-			ensured = true
-		}
-		if !ensured {
-			createListenerRequest := clb.NewCreateListenerRequest()
-			createListenerRequest.Ports = common.Int64Ptrs([]int64{int64(port.Port)})
-			createListenerRequest.ListenerNames = common.StringPtrs([]string{port.Name})
-			createListenerRequest.Protocol = common.StringPtr(string(port.Protocol))
-			createListenerRequest.LoadBalancerId = common.StringPtr(*loadBalancer.LoadBalancerId)
-			createListenerRequest.HealthCheck = cloud.buildHealthCheck(service)
-			listenersToCreate = append(listenersToCreate, createListenerRequest)
-		}
-	}
-
-	listenersToDelete := make([]*clb.DeleteListenerRequest, 0)
-	for _, listener := range loadBalancerListeners {
-		used := false
-		if isExist(*listener.ListenerId, usedListenerIds) {
-			used = true
-		}
-		if !used {
-			deleteListenerRequest := clb.NewDeleteListenerRequest()
-			deleteListenerRequest.LoadBalancerId = common.StringPtr(*loadBalancer.LoadBalancerId)
-			deleteListenerRequest.ListenerId = common.StringPtr(*listener.ListenerId)
-			listenersToDelete = append(listenersToDelete, deleteListenerRequest)
-		}
-	}
-
-	for _, usedListener := range listenersToCreate {
-		response, err := cloud.clb.CreateListener(usedListener)
-		klog.V(3).Infof("tencentcloud.ensureLoadBalancerListeners: create listener: CLB_ID:%s, Port:%+v, name:%+v\n", *usedListener.LoadBalancerId, *usedListener.Ports[0], *usedListener.ListenerNames[0])
+		deleteListenersRequest := clb.NewDeleteLoadBalancerListenersRequest()
+		deleteListenersRequest.LoadBalancerId = common.StringPtr(*loadBalancer.LoadBalancerId)
+		deleteListenersRequest.ListenerIds = common.StringPtrs(listenerIds)
+		deleteListenersRequest.SetContext(ctx)
+		response, err := cloud.clb.DeleteLoadBalancerListeners(deleteListenersRequest)
+		klog.V(3).Infof("tencentcloud.ensureLoadBalancerListeners: delete listeners: CLB_ID:%s, ListenerIds:%v\n", *loadBalancer.LoadBalancerId, listenerIds)
 
 		if err != nil {
-			klog.Warningf("tencentcloud.ensureLoadBalancerListeners: create listener (CLB_ID:%s) error: %s\n", *usedListener.LoadBalancerId, err)
+			klog.Warningf("tencentcloud.ensureLoadBalancerListeners: delete listeners %v error: %s\n", listenerIds, err)
 			klog.V(3).Infof("tencentcloud.ensureLoadBalancerListeners: return: %v\n", err)
 			return err
 		}
-		klog.V(3).Infof("tencentcloud.ensureLoadBalancerListeners: create listener: CLB_ID:%s, Port:%+v, name:%+v, RequestID:%s\n", *usedListener.LoadBalancerId, *usedListener.Ports[0], *usedListener.ListenerNames[0], *response.Response.RequestId)
-		if err := cloud.waitApiTaskDone(response.Response.RequestId); err != nil {
+		klog.V(3).Infof("tencentcloud.ensureLoadBalancerListeners: delete listeners: CLB_ID:%s, ListenerIds:%v, RequestID:%s\n", *loadBalancer.LoadBalancerId, listenerIds, *response.Response.RequestId)
+		if err := cloud.taskWaiter.Wait(ctx, *response.Response.RequestId); err != nil {
 			klog.Warningf("tencentcloud.ensureLoadBalancerListeners: return: %v\n", err)
 			return err
 		}
 	}
 
-	for _, unusedListener := range listenersToDelete {
-		response, err := cloud.clb.DeleteListener(unusedListener)
-		klog.V(3).Infof("tencentcloud.ensureLoadBalancerListeners: delete listener: CLB_ID:%s, ListenerId:%s\n", *unusedListener.LoadBalancerId, *unusedListener.ListenerId)
+	tasks := make([]string, 0)
+	for _, rename := range listenersToModify {
+		modifyListenerRequest := clb.NewModifyListenerRequest()
+		modifyListenerRequest.LoadBalancerId = common.StringPtr(*loadBalancer.LoadBalancerId)
+		modifyListenerRequest.ListenerId = common.StringPtr(*rename.listener.ListenerId)
+		modifyListenerRequest.ListenerName = common.StringPtr(rename.name)
+		modifyListenerRequest.SetContext(ctx)
+		response, err := cloud.clb.ModifyListener(modifyListenerRequest)
+		klog.V(3).Infof("tencentcloud.ensureLoadBalancerListeners: modify listener: CLB_ID:%s, ListenerId:%s, name:%s\n", *loadBalancer.LoadBalancerId, *rename.listener.ListenerId, rename.name)
 
 		if err != nil {
-			klog.Warningf("tencentcloud.ensureLoadBalancerListeners: delete listener %s error: %s\n", *unusedListener.ListenerId, err)
+			klog.Warningf("tencentcloud.ensureLoadBalancerListeners: modify listener %s error: %s\n", *rename.listener.ListenerId, err)
 			klog.V(3).Infof("tencentcloud.ensureLoadBalancerListeners: return: %v\n", err)
 			return err
 		}
-		klog.V(3).Infof("tencentcloud.ensureLoadBalancerListeners: delete listener: CLB_ID:%s, ListenerId:%s, RequestID:%s\n", *unusedListener.LoadBalancerId, *unusedListener.ListenerId, *response.Response.RequestId)
-		if err := cloud.waitApiTaskDone(response.Response.RequestId); err != nil {
-			klog.Warningf("tencentcloud.ensureLoadBalancerListeners: return: %v\n", err)
+		klog.V(3).Infof("tencentcloud.ensureLoadBalancerListeners: modify listener: CLB_ID:%s, ListenerId:%s, RequestID:%s\n", *loadBalancer.LoadBalancerId, *rename.listener.ListenerId, *response.Response.RequestId)
+		tasks = append(tasks, *response.Response.RequestId)
+	}
+
+	for _, createListenerRequest := range buildCreateListenerRequests(*loadBalancer.LoadBalancerId, listenersToCreate, cloud.buildHealthCheck(service)) {
+		createListenerRequest.SetContext(ctx)
+		response, err := cloud.clb.CreateListener(createListenerRequest)
+		klog.V(3).Infof("tencentcloud.ensureLoadBalancerListeners: create listeners: CLB_ID:%s, Protocol:%s, names:%v\n", *createListenerRequest.LoadBalancerId, *createListenerRequest.Protocol, common.StringValues(createListenerRequest.ListenerNames))
+
+		if err != nil {
+			klog.Warningf("tencentcloud.ensureLoadBalancerListeners: create listeners (CLB_ID:%s) error: %s\n", *createListenerRequest.LoadBalancerId, err)
+			klog.V(3).Infof("tencentcloud.ensureLoadBalancerListeners: return: %v\n", err)
 			return err
 		}
+		klog.V(3).Infof("tencentcloud.ensureLoadBalancerListeners: create listeners: CLB_ID:%s, ListenerIds:%v, RequestID:%s\n", *createListenerRequest.LoadBalancerId, common.StringValues(response.Response.ListenerIds), *response.Response.RequestId)
+		tasks = append(tasks, *response.Response.RequestId)
+	}
+
+	if err := cloud.taskWaiter.WaitAll(ctx, tasks); err != nil {
+		klog.Warningf("tencentcloud.ensureLoadBalancerListeners: return: %v\n", err)
+		return err
 	}
 
 	klog.V(3).Infof("tencentcloud.ensureLoadBalancerListeners: return: %s\n", "nil")
 	return nil
 }
 
-// waitApiTaskDone wait Tencent Cloud async api task done
-// tasks *[]string requestId list
-func (cloud *Cloud) waitApiTaskDone(task *string) error {
-	klog.V(3).Infof("tencentcloud.waitApiTaskDone(\"%s\"): entered\n", *task)
-	for i := 0; i < 30; i++ {
-		status, err := cloud.getTencentCloudApiTaskStatus(task)
-		if err != nil {
-			return err
+// listenerKey identifies a listener by protocol and port, the same pair the CLB enforces to be unique
+type listenerKey struct {
+	protocol string
+	port     int64
+}
+
+// listenerRename is an existing listener whose name no longer matches its service port
+type listenerRename struct {
+	listener *clb.Listener
+	name     string
+}
+
+// diffLoadBalancerListeners compares service ports with existing listeners by (protocol, port) and
+// returns the ports to create listeners for, the listeners to rename and the listeners to delete
+func diffLoadBalancerListeners(ports []v1.ServicePort, listeners []*clb.Listener) ([]v1.ServicePort, []listenerRename, []*clb.Listener) {
+	existing := make(map[listenerKey]*clb.Listener, len(listeners))
+	for _, listener := range listeners {
+		existing[listenerKey{protocol: *listener.Protocol, port: *listener.Port}] = listener
+	}
+
+	desired := make(map[listenerKey]bool, len(ports))
+	toCreate := make([]v1.ServicePort, 0)
+	toModify := make([]listenerRename, 0)
+	for _, port := range ports {
+		key := listenerKey{protocol: string(port.Protocol), port: int64(port.Port)}
+		if desired[key] {
+			continue
 		}
-		switch status {
-		case 0:
-			klog.V(2).Infof("tencentcloud.waitApiTaskDone: Task %s executed successfully.\n", *task)
-			return nil
-		case 1:
-			klog.Warningf("tencentcloud.waitApiTaskDone: Task %s executed failed!\n", *task)
-			return nil
-		case 2:
-			klog.V(2).Infof("tencentcloud.waitApiTaskDone: Task %s executing, Current number: %d, Try again next time.\n", *task, i)
-			break
-		default:
-			klog.Warningf("tencentcloud.waitApiTaskDone: Task %s executed return a not expected value: %d\n", *task, status)
-			return errors.New("task" + *task + " executed return a not expected value:" + strconv.FormatInt(status, 10))
+		desired[key] = true
+
+		listener, ok := existing[key]
+		if !ok {
+			toCreate = append(toCreate, port)
+			continue
+		}
+		if listener.ListenerName == nil || *listener.ListenerName != port.Name {
+			toModify = append(toModify, listenerRename{listener: listener, name: port.Name})
 		}
-		time.Sleep(time.Duration(1) * time.Second)
 	}
-	klog.Warningf("tencentcloud.waitApiTasksDone: task %s execute timeout!\n", *task)
-	return errors.New("task" + *task + " execute timeout:")
-}
 
-// getTencentCloudApiTaskStatus return Tencent Cloud async api task status
-// return: 任务的当前状态。 0：成功，1：失败，2：进行中, 3: error。
-func (cloud *Cloud) getTencentCloudApiTaskStatus(taskId *string) (int64, error) {
-	klog.V(3).Infof("tencentcloud.getTencentCloudApiTaskStatus(\"%s\"): entered\n", *taskId)
-
-	request := clb.NewDescribeTaskStatusRequest()
-	request.TaskId = common.StringPtr(*taskId)
-	response, err := cloud.clb.DescribeTaskStatus(request)
-	if err != nil {
-		klog.Warningf("tencentcloud.getTencentCloudApiTaskStatus: Get error: %s\n", err)
-		klog.V(3).Infof("tencentcloud.getTencentCloudApiTaskStatus: return(taskId:%s): 3\n", *taskId)
-		return 3, err
+	toDelete := make([]*clb.Listener, 0)
+	for _, listener := range listeners {
+		if !desired[listenerKey{protocol: *listener.Protocol, port: *listener.Port}] {
+			toDelete = append(toDelete, listener)
+		}
 	}
 
-	klog.V(3).Infof("tencentcloud.getTencentCloudApiTaskStatus: return(taskId:%s): %d\n", *taskId, *response.Response.Status)
-	// *response.Response.Status: 任务的当前状态。 0：成功，1：失败，2：进行中。
-	return *response.Response.Status, nil
+	return toCreate, toModify, toDelete
+}
+
+// buildCreateListenerRequests groups ports with identical listener settings into one multi-port CreateListener request.
+// The health check is shared by the whole service, so ports only differ by protocol.
+func buildCreateListenerRequests(loadBalancerId string, ports []v1.ServicePort, healthCheck *clb.HealthCheck) []*clb.CreateListenerRequest {
+	requests := make([]*clb.CreateListenerRequest, 0)
+	byProtocol := make(map[v1.Protocol]*clb.CreateListenerRequest)
+	for _, port := range ports {
+		request, ok := byProtocol[port.Protocol]
+		if !ok {
+			request = clb.NewCreateListenerRequest()
+			request.LoadBalancerId = common.StringPtr(loadBalancerId)
+			request.Protocol = common.StringPtr(string(port.Protocol))
+			request.HealthCheck = healthCheck
+			byProtocol[port.Protocol] = request
+			requests = append(requests, request)
+		}
+		request.Ports = append(request.Ports, common.Int64Ptr(int64(port.Port)))
+		request.ListenerNames = append(request.ListenerNames, common.StringPtr(port.Name))
+	}
+	return requests
 }
 
 // getNodeLabelKey return node annotations key for service annotations(ServiceAnnotationLoadBalancerNodeLabelKey)
@@ -414,29 +440,29 @@ func (cloud *Cloud) ensureLoadBalancerBackends(ctx context.Context, clusterName
 	klog.V(3).Infof("tencentcloud.ensureLoadBalancerBackends(\"%s, %T, %T\"): entered\n", clusterName, service, nodes)
 
 	loadBalancerName := cloud.getLoadBalancerName(ctx, clusterName, service)
-	loadBalancer, err := cloud.getLoadBalancer(loadBalancerName, service)
+	loadBalancer, err := cloud.getLoadBalancer(ctx, loadBalancerName, service)
 	if err != nil {
 		klog.Warningf("tencentcloud.ensureLoadBalancerBackends: Get error: %s\n", err)
 		klog.V(3).Infof("tencentcloud.ensureLoadBalancerBackends: return: %s\n", "nil")
 		return err
 	}
 
-	nodeLanIps := make([]string, 0)
+	nodeNames := make([]string, 0)
 	for _, node := range nodes {
 		if _, ok := node.Labels[cloud.getNodeLabelKey(service)]; ok {
 			if node.Labels[cloud.getNodeLabelKey(service)] == cloud.getNodeLabelValue(service) {
-				nodeLanIps = append(nodeLanIps, node.Name)
+				nodeNames = append(nodeNames, node.Name)
 			}
 		}
 	}
 
-	if len(nodeLanIps) == 0 {
+	if len(nodeNames) == 0 {
 		klog.Warningf("tencentcloud.ensureLoadBalancerBackends: return error: can't found nodes base on label: " + cloud.getNodeLabelKey(service) + "=" + cloud.getNodeLabelValue(service) + "\n")
 		return errors.New("can't found nodes base on label: " + cloud.getNodeLabelKey(service) + "=" + cloud.getNodeLabelValue(service))
 	}
 
 	//instancesInMultiVpc, err := cloud.getInstancesByMultiLanIp(ctx, nodeLanIps)
-	instancesInMultiVpc, err := cloud.getInstanceByInstancePrivateIps(ctx, nodeLanIps)
+	instancesInMultiVpc, err := cloud.getInstancesByNodeNames(ctx, nodeNames)
 
 	if err != nil {
 		klog.Warningf("tencentcloud.ensureLoadBalancerBackends: Get error: %s\n", err)
@@ -453,6 +479,7 @@ func (cloud *Cloud) ensureLoadBalancerBackends(ctx context.Context, clusterName
 
 	request := clb.NewDescribeTargetsRequest()
 	request.LoadBalancerId = common.StringPtr(*loadBalancer.LoadBalancerId)
+	request.SetContext(ctx)
 	response, err := cloud.clb.DescribeTargets(request)
 
 	if err != nil {
@@ -499,7 +526,7 @@ func (cloud *Cloud) ensureLoadBalancerBackends(ctx context.Context, clusterName
 					InstanceId: backend.InstanceId,
 					Port:       backend.Port,
 				})
-				klog.V(3).Infof("tencentcloud.ensureLoadBalancerBackends: Add to backendsToDelete, instance.InstanceId: %s", backend.InstanceId)
+				klog.V(3).Infof("tencentcloud.ensureLoadBalancerBackends: Add to backendsToDelete, instance.InstanceId: %s", *backend.InstanceId)
 			}
 		}
 
@@ -508,7 +535,7 @@ func (cloud *Cloud) ensureLoadBalancerBackends(ctx context.Context, clusterName
 			for i := 0; i < count; i++ {
 				backend = append(backend, backendsToDelete[i])
 				if (i > 0 && (i+1)%20 == 0) || i == count-1 {
-					err := cloud.deleteLoadBalancerBackends(*loadBalancer.LoadBalancerId, *forwardListener.ListenerId, backend)
+					err := cloud.deleteLoadBalancerBackends(ctx, *loadBalancer.LoadBalancerId, *forwardListener.ListenerId, backend)
 					if err != nil {
 						klog.V(3).Infof("tencentcloud.ensureLoadBalancerBackends: return: %s %v\n", "", err)
 						return err
@@ -565,7 +592,7 @@ func (cloud *Cloud) ensureLoadBalancerBackends(ctx context.Context, clusterName
 			for i := 0; i < count; i++ {
 				backend = append(backend, backendsToAdd[i])
 				if (i > 0 && (i+1)%20 == 0) || i == count-1 {
-					err := cloud.addLoadBalancerBackends(*loadBalancer.LoadBalancerId, *forwardListener.ListenerId, backend)
+					err := cloud.addLoadBalancerBackends(ctx, *loadBalancer.LoadBalancerId, *forwardListener.ListenerId, backend)
 					if err != nil {
 						klog.Warningf("tencentcloud.ensureLoadBalancerBackends: Get error: %s, backend count=%d\n", err, len(backend))
 						klog.V(3).Infof("tencentcloud.ensureLoadBalancerBackends: return: %v\n", err)
@@ -583,7 +610,7 @@ func (cloud *Cloud) ensureLoadBalancerBackends(ctx context.Context, clusterName
 }
 
 // addLoadBalancerBackends add Tencent Cloud Load Balancer Backends, return Tencent Cloud RequestId
-func (cloud *Cloud) addLoadBalancerBackends(loadBalancerId string, listenerId string, backends []*clb.Target) error {
+func (cloud *Cloud) addLoadBalancerBackends(ctx context.Context, loadBalancerId string, listenerId string, backends []*clb.Target) error {
 	klog.V(3).Infof("tencentcloud.addLoadBalancerBackends(\"%s %s %T\"): entered\n", loadBalancerId, listenerId, backends)
 	for _, backend := range backends {
 		klog.V(3).Infof("tencentcloud.addLoadBalancerBackends: add backend instanceId: %s\n", *backend.InstanceId)
@@ -594,6 +621,7 @@ func (cloud *Cloud) addLoadBalancerBackends(loadBalancerId string, listenerId st
 	request.ListenerId = common.StringPtr(listenerId)
 	request.Targets = backends
 
+	request.SetContext(ctx)
 	response, err := cloud.clb.RegisterTargets(request)
 	if _, ok := err.(*cloudErrors.TencentCloudSDKError); ok {
 		klog.Warningf("tencentcloud.addLoadBalancerBackends: tencentcloud API error: %s\n", err)
@@ -606,7 +634,7 @@ func (cloud *Cloud) addLoadBalancerBackends(loadBalancerId string, listenerId st
 		return err
 	}
 
-	if err := cloud.waitApiTaskDone(response.Response.RequestId); err != nil {
+	if err := cloud.taskWaiter.Wait(ctx, *response.Response.RequestId); err != nil {
 		klog.Warningf("tencentcloud.addLoadBalancerBackends: return: %v\n", err)
 		return err
 	}
@@ -616,7 +644,7 @@ func (cloud *Cloud) addLoadBalancerBackends(loadBalancerId string, listenerId st
 }
 
 // deleteLoadBalancerBackends delete Tencent Cloud Load Balancer Backends, return Tencent Cloud RequestId
-func (cloud *Cloud) deleteLoadBalancerBackends(loadBalancerId string, listenerId string, backends []*clb.Target) error {
+func (cloud *Cloud) deleteLoadBalancerBackends(ctx context.Context, loadBalancerId string, listenerId string, backends []*clb.Target) error {
 	klog.V(3).Infof("tencentcloud.deleteLoadBalancerBackends(\"%s %s %T\"): entered\n", loadBalancerId, listenerId, backends)
 	for _, backend := range backends {
 		klog.V(3).Infof("tencentcloud.deleteLoadBalancerBackends: delete backend instanceId: %s\n", *backend.InstanceId)
@@ -626,6 +654,7 @@ func (cloud *Cloud) deleteLoadBalancerBackends(loadBalancerId string, listenerId
 	request.LoadBalancerId = common.StringPtr(loadBalancerId)
 	request.ListenerId = common.StringPtr(listenerId)
 	request.Targets = backends
+	request.SetContext(ctx)
 	response, err := cloud.clb.DeregisterTargets(request)
 
 	if _, ok := err.(*cloudErrors.TencentCloudSDKError); ok {
@@ -639,7 +668,7 @@ func (cloud *Cloud) deleteLoadBalancerBackends(loadBalancerId string, listenerId
 		return err
 	}
 
-	if err := cloud.waitApiTaskDone(response.Response.RequestId); err != nil {
+	if err := cloud.taskWaiter.Wait(ctx, *response.Response.RequestId); err != nil {
 		klog.Warningf("tencentcloud.addLoadBalancerBackends: return: %v\n", err)
 		return err
 	}
@@ -675,8 +704,50 @@ func (cloud *Cloud) createLoadBalancer(ctx context.Context, clusterName string,
 	request.VpcId = common.StringPtr(cloud.txConfig.VpcId)
 	request.Tags = cloud.getLBTags(ctx, service)
 	request.LoadBalancerPassToTarget = &loadBalancerPassToTarget
-
-	response, err := cloud.clb.CreateLoadBalancer(request)
+	request.ClientToken = common.StringPtr(getLoadBalancerClientToken(service, *request.LoadBalancerType, request.SubnetId))
+
+	var response *clb.CreateLoadBalancerResponse
+	var err error
+	for renewals := 0; ; renewals++ {
+		request.SetContext(ctx)
+		response, err = cloud.clb.CreateLoadBalancer(request)
+		if err != nil || len(response.Response.LoadBalancerIds) == 0 {
+			break
+		}
+		// the token of a CLB deleted since returns the deleted CLB instead of creating one
+		id := *response.Response.LoadBalancerIds[0]
+		exists, describeErr := cloud.loadBalancerExists(ctx, id)
+		if describeErr != nil {
+			klog.V(3).Infof("tencentcloud.createLoadBalancer: loadBalancerName: %s, return: %v\n", loadBalancerName, describeErr)
+			return describeErr
+		}
+		if exists {
+			break
+		}
+		if renewals >= loadBalancerClientTokenMaxRenewals {
+			err := fmt.Errorf("client token still returns the deleted CLB ID %s after %d renewals", id, renewals)
+			klog.Warningf("tencentcloud.createLoadBalancer: loadBalancerName: %s, Get error: %s\n", loadBalancerName, err)
+			return err
+		}
+		klog.Infof("tencentcloud.createLoadBalancer: loadBalancerName: %s, client token of deleted CLB ID: %s, renew it\n", loadBalancerName, id)
+		request.ClientToken = common.StringPtr(renewLoadBalancerClientToken(*request.ClientToken, id))
+	}
+	if err != nil && isAmbiguousError(err) {
+		// the CLB may have been created even though we never got the response
+		klog.Warningf("tencentcloud.createLoadBalancer: loadBalancerName: %s, ambiguous error: %s, try to adopt\n", loadBalancerName, err)
+		if loadBalancer, adoptErr := cloud.adoptLoadBalancer(ctx, loadBalancerName, service); adoptErr == nil {
+			klog.Infof("tencentcloud.createLoadBalancer: loadBalancerName: %s, adopted CLB ID: %s\n", loadBalancerName, *loadBalancer.LoadBalancerId)
+			// the adopted CLB may still be creating, the same client token returns its create request to wait on
+			request.SetContext(ctx)
+			response, err = cloud.clb.CreateLoadBalancer(request)
+			if err == nil && (len(response.Response.LoadBalancerIds) == 0 || *response.Response.LoadBalancerIds[0] != *loadBalancer.LoadBalancerId) {
+				err = fmt.Errorf("client token of the adopted CLB ID %s returns CLB IDs %v", *loadBalancer.LoadBalancerId, common.StringValues(response.Response.LoadBalancerIds))
+			}
+			if err != nil {
+				cloud.invalidateLoadBalancerCache(loadBalancerName)
+			}
+		}
+	}
 	if _, ok := err.(*cloudErrors.TencentCloudSDKError); ok {
 		klog.Warningf("tencentcloud.createLoadBalancer: loadBalancerName: %s, tencentcloud API error: %s\n", loadBalancerName, err)
 		klog.V(3).Infof("tencentcloud.createLoadBalancer: loadBalancerName: %s, return: %v\n", loadBalancerName, err)
@@ -684,20 +755,98 @@ func (cloud *Cloud) createLoadBalancer(ctx context.Context, clusterName string,
 	}
 	if err != nil {
 		klog.Warningf("tencentcloud.createLoadBalancer: loadBalancerName: %s, Get error: %s\n", loadBalancerName, err)
-		klog.V(3).Infof("tencentcloud.createLoadBalancer: loadBalancerName: %s, return: %s %v\n", "", loadBalancerName, err)
+		klog.V(3).Infof("tencentcloud.createLoadBalancer: loadBalancerName: %s, return: %v\n", loadBalancerName, err)
 		return err
 	}
-	klog.V(3).Infof("tencentcloud.createLoadBalancer: loadBalancerName: %s, requestId: %s, VpcID: %s\n", loadBalancerName, *response.Response.RequestId)
+	klog.V(3).Infof("tencentcloud.createLoadBalancer: loadBalancerName: %s, requestId: %s, VpcID: %s\n", loadBalancerName, *response.Response.RequestId, cloud.txConfig.VpcId)
 
-	if err := cloud.waitApiTaskDone(response.Response.RequestId); err != nil {
+	if err := cloud.taskWaiter.Wait(ctx, *response.Response.RequestId); err != nil {
 		klog.Warningf("tencentcloud.createLoadBalancer: loadBalancerName: %s, return:  %v\n", loadBalancerName, err)
 		return err
 	}
+	cloud.invalidateLoadBalancerCache(loadBalancerName)
 
 	klog.V(3).Infof("tencentcloud.createLoadBalancer: exit\n")
 	return nil
 }
 
+// getLoadBalancerClientToken return a deterministic ClientToken for creating the service CLB, so a retried create is idempotent.
+// The desired type and subnet are part of the token because a recreated CLB must not be deduplicated against the deleted one.
+func getLoadBalancerClientToken(service *v1.Service, loadBalancerType string, subnetId *string) string {
+	subnet := ""
+	if subnetId != nil {
+		subnet = *subnetId
+	}
+	sum := sha256.Sum256([]byte(string(service.UID) + "/" + loadBalancerType + "/" + subnet))
+	return hex.EncodeToString(sum[:])
+}
+
+// renewLoadBalancerClientToken return the ClientToken replacing token once the CLB created with it is deleted,
+// deterministic too, so a retried recreate is still idempotent
+func renewLoadBalancerClientToken(token string, deletedLoadBalancerId string) string {
+	sum := sha256.Sum256([]byte(token + "/" + deletedLoadBalancerId))
+	return hex.EncodeToString(sum[:])
+}
+
+// loadBalancerExists check the CLB with the id exists
+func (cloud *Cloud) loadBalancerExists(ctx context.Context, loadBalancerId string) (bool, error) {
+	request := clb.NewDescribeLoadBalancersRequest()
+	request.LoadBalancerIds = common.StringPtrs([]string{loadBalancerId})
+	request.SetContext(ctx)
+	response, err := cloud.clb.DescribeLoadBalancers(request)
+	if err != nil {
+		klog.Warningf("tencentcloud.loadBalancerExists: Get error: %s\n", err)
+		return false, err
+	}
+	return len(response.Response.LoadBalancerSet) > 0, nil
+}
+
+// isAmbiguousError check whether a failed create may have succeeded on the Tencent Cloud side
+func isAmbiguousError(err error) bool {
+	sdkErr, ok := err.(*cloudErrors.TencentCloudSDKError)
+	if !ok {
+		return true
+	}
+	code := sdkErr.GetCode()
+	return code == "InternalError" || strings.HasPrefix(code, "InternalError.") ||
+		code == "ClientError.NetworkError" || code == "ClientError.IOError" ||
+		code == "ClientError.HttpStatusCodeError" || code == "ClientError.ParseJsonError"
+}
+
+// adoptLoadBalancer look up the service CLB by tag after an ambiguous create failure and cache it
+func (cloud *Cloud) adoptLoadBalancer(ctx context.Context, name string, service *v1.Service) (*clb.LoadBalancer, error) {
+	klog.V(3).Infof("tencentcloud.adoptLoadBalancer(\"%s\"): entered\n", name)
+
+	request := clb.NewDescribeLoadBalancersRequest()
+	request.Filters = cloud.getLoadBalancerFilter(service)
+	request.SetContext(ctx)
+	response, err := cloud.clb.DescribeLoadBalancers(request)
+	if err != nil {
+		klog.Warningf("tencentcloud.adoptLoadBalancer: Get error: %s\n", err)
+		return nil, err
+	}
+
+	var adopted *clb.LoadBalancer
+	for _, loadBalancer := range response.Response.LoadBalancerSet {
+		if loadBalancer.LoadBalancerName == nil || *loadBalancer.LoadBalancerName != name {
+			continue
+		}
+		if adopted != nil {
+			klog.Warningf("tencentcloud.adoptLoadBalancer: return(name: %s): nil, %v\n", name, ErrCloudLoadBalancerDuplicated)
+			return nil, ErrCloudLoadBalancerDuplicated
+		}
+		adopted = loadBalancer
+	}
+	if adopted == nil {
+		klog.V(3).Infof("tencentcloud.adoptLoadBalancer: return(name: %s): nil, %v\n", name, ErrCloudLoadBalancerNotFound)
+		return nil, ErrCloudLoadBalancerNotFound
+	}
+
+	cloud.loadBalancerCache.Set(cacheNamePreCLB+name, adopted)
+	klog.V(3).Infof("tencentcloud.adoptLoadBalancer: return(name: %s, CLB ID: %s): %T, nil\n", name, *adopted.LoadBalancerId, adopted)
+	return adopted, nil
+}
+
 // getTags get new load balancer tags
 func (cloud *Cloud) getLBTags(ctx context.Context, service *v1.Service) []*clb.TagInfo {
 	var tags []*clb.TagInfo
@@ -764,7 +913,7 @@ func (cloud *Cloud) deleteLoadBalancer(ctx context.Context, clusterName string,
 	klog.V(3).Infof("tencentcloud.deleteLoadBalancer(\"%s, %T\"): entered\n", clusterName, service)
 
 	loadBalancerName := cloud.getLoadBalancerName(ctx, clusterName, service)
-	loadBalancer, err := cloud.getLoadBalancer(loadBalancerName, service)
+	loadBalancer, err := cloud.getLoadBalancer(ctx, loadBalancerName, service)
 	if err != nil {
 		if err == ErrCloudLoadBalancerNotFound {
 			klog.Warningf("tencentcloud.deleteLoadBalancer: Get error: %s\n", err)
@@ -778,6 +927,7 @@ func (cloud *Cloud) deleteLoadBalancer(ctx context.Context, clusterName string,
 	request := clb.NewDeleteLoadBalancerRequest()
 	request.LoadBalancerIds = common.StringPtrs([]string{*loadBalancer.LoadBalancerId})
 	klog.V(3).Infof("tencentcloud.deleteLoadBalancer: LoadBalancerId: %s\n", *loadBalancer.LoadBalancerId)
+	request.SetContext(ctx)
 	response, err := cloud.clb.DeleteLoadBalancer(request)
 	if _, ok := err.(*cloudErrors.TencentCloudSDKError); ok {
 		klog.Warningf("tencentcloud.deleteLoadBalancer: tencentcloud API error: %s\n", err)
@@ -790,13 +940,10 @@ func (cloud *Cloud) deleteLoadBalancer(ctx context.Context, clusterName string,
 		return err
 	}
 	klog.V(3).Infof("tencentcloud.deleteLoadBalancer: requestId: %s\n", *response.Response.RequestId)
+	cloud.invalidateLoadBalancerCache(loadBalancerName)
+	cloud.invalidateLoadBalancerListenersCache(*loadBalancer.LoadBalancerId)
 
-	if err := cloud.waitApiTaskDone(response.Response.RequestId); err != nil {
-		if cacheKey := cacheNamePreCLB + loadBalancerName; cloud.cache.Delete(cacheKey) {
-			klog.Infof("tencentcloud.deleteLoadBalancer: delete cache done. key: %s\n", cacheKey)
-		} else {
-			klog.Warningf("tencentcloud.deleteLoadBalancer: delete cache fail. key: %s\n", cacheKey)
-		}
+	if err := cloud.taskWaiter.Wait(ctx, *response.Response.RequestId); err != nil {
 		klog.Warningf("tencentcloud.deleteLoadBalancer: return: %v\n", err)
 		return err
 	}
@@ -0,0 +1,305 @@
+package tencentcloud
+
+import (
+	"context"
+	"testing"
+
+	clb "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/clb/v20180317"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	cloudErrors "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/errors"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func newTestListener(id, protocol string, port int64, name string) *clb.Listener {
+	return &clb.Listener{
+		ListenerId:   common.StringPtr(id),
+		Protocol:     common.StringPtr(protocol),
+		Port:         common.Int64Ptr(port),
+		ListenerName: common.StringPtr(name),
+	}
+}
+
+func TestDiffLoadBalancerListeners(t *testing.T) {
+	listeners := []*clb.Listener{
+		newTestListener("lbl-http", "TCP", 80, "http"),
+		newTestListener("lbl-renamed", "TCP", 443, "https"),
+		newTestListener("lbl-dns", "TCP", 53, "dns"),
+		newTestListener("lbl-stale", "TCP", 8080, "stale"),
+	}
+	ports := []v1.ServicePort{
+		{Name: "http", Protocol: v1.ProtocolTCP, Port: 80},
+		{Name: "tls", Protocol: v1.ProtocolTCP, Port: 443},
+		{Name: "dns", Protocol: v1.ProtocolUDP, Port: 53},
+	}
+
+	toCreate, toModify, toDelete := diffLoadBalancerListeners(ports, listeners)
+
+	if len(toCreate) != 1 || toCreate[0].Protocol != v1.ProtocolUDP || toCreate[0].Port != 53 {
+		t.Errorf("expected UDP/53 to be created, got %+v", toCreate)
+	}
+	if len(toModify) != 1 || *toModify[0].listener.ListenerId != "lbl-renamed" || toModify[0].name != "tls" {
+		t.Errorf("expected lbl-renamed to be renamed to tls, got %+v", toModify)
+	}
+	deleted := make(map[string]bool)
+	for _, listener := range toDelete {
+		deleted[*listener.ListenerId] = true
+	}
+	if len(deleted) != 2 || !deleted["lbl-dns"] || !deleted["lbl-stale"] {
+		t.Errorf("expected lbl-dns and lbl-stale to be deleted, got %v", deleted)
+	}
+}
+
+func TestDiffLoadBalancerListenersInSync(t *testing.T) {
+	listeners := []*clb.Listener{newTestListener("lbl-http", "TCP", 80, "http")}
+	ports := []v1.ServicePort{{Name: "http", Protocol: v1.ProtocolTCP, Port: 80}}
+
+	toCreate, toModify, toDelete := diffLoadBalancerListeners(ports, listeners)
+	if len(toCreate) != 0 || len(toModify) != 0 || len(toDelete) != 0 {
+		t.Errorf("expected no changes, got create=%d modify=%d delete=%d", len(toCreate), len(toModify), len(toDelete))
+	}
+}
+
+func TestBuildCreateListenerRequests(t *testing.T) {
+	ports := []v1.ServicePort{
+		{Name: "http", Protocol: v1.ProtocolTCP, Port: 80},
+		{Name: "dns", Protocol: v1.ProtocolUDP, Port: 53},
+		{Name: "https", Protocol: v1.ProtocolTCP, Port: 443},
+	}
+	healthCheck := &clb.HealthCheck{HealthSwitch: common.Int64Ptr(1)}
+
+	requests := buildCreateListenerRequests("lb-test", ports, healthCheck)
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(requests))
+	}
+	tcp, udp := requests[0], requests[1]
+	if *tcp.Protocol != "TCP" || len(tcp.Ports) != 2 || *tcp.Ports[0] != 80 || *tcp.Ports[1] != 443 {
+		t.Errorf("unexpected TCP request: %s", tcp.ToJsonString())
+	}
+	if *tcp.ListenerNames[0] != "http" || *tcp.ListenerNames[1] != "https" {
+		t.Errorf("listener names not aligned with ports: %s", tcp.ToJsonString())
+	}
+	if *udp.Protocol != "UDP" || len(udp.Ports) != 1 || *udp.Ports[0] != 53 {
+		t.Errorf("unexpected UDP request: %s", udp.ToJsonString())
+	}
+	if *tcp.LoadBalancerId != "lb-test" || tcp.HealthCheck != healthCheck {
+		t.Errorf("settings not propagated: %s", tcp.ToJsonString())
+	}
+}
+
+func newTestService() *v1.Service {
+	return &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "nginx",
+			Namespace:   "default",
+			UID:         types.UID("8a4c6f0e-0d5e-4b8f-9d41-6f1d3c2b7a90"),
+			Annotations: map[string]string{ServiceAnnotationLoadBalancerType: LoadBalancerTypePublic},
+		},
+		Spec: v1.ServiceSpec{
+			Type:            v1.ServiceTypeLoadBalancer,
+			SessionAffinity: v1.ServiceAffinityNone,
+			Ports: []v1.ServicePort{
+				{Name: "http", Protocol: v1.ProtocolTCP, Port: 80, NodePort: 30080},
+			},
+		},
+	}
+}
+
+func TestGetLoadBalancerClientToken(t *testing.T) {
+	service := newTestService()
+	token := getLoadBalancerClientToken(service, ClbLoadBalancerTypePublic, nil)
+	if len(token) > 64 {
+		t.Errorf("client token too long: %d", len(token))
+	}
+	if token != getLoadBalancerClientToken(service, ClbLoadBalancerTypePublic, nil) {
+		t.Errorf("client token is not deterministic")
+	}
+	if token == getLoadBalancerClientToken(service, ClbLoadBalancerTypePrivate, common.StringPtr("subnet-1")) {
+		t.Errorf("recreated CLB must use another client token")
+	}
+	// a spec edit between an ambiguous create and its retry must not change the token
+	service.Generation++
+	if token != getLoadBalancerClientToken(service, ClbLoadBalancerTypePublic, nil) {
+		t.Errorf("client token changed with the service generation")
+	}
+	renewed := renewLoadBalancerClientToken(token, "lb-1")
+	if renewed == token || renewed != renewLoadBalancerClientToken(token, "lb-1") || len(renewed) > 64 {
+		t.Errorf("renewed client token %q is not a deterministic new token", renewed)
+	}
+}
+
+func TestCreateLoadBalancerAdoptsAfterAmbiguousFailure(t *testing.T) {
+	fake := newFakeCLB()
+	fake.createLoadBalancerErr = cloudErrors.NewTencentCloudSDKError("ClientError.NetworkError", "read: connection reset by peer", "")
+	cloud := newTestCloud(fake, newFakeCVM())
+	service := newTestService()
+
+	if err := cloud.createLoadBalancer(context.Background(), "kubernetes", service); err != nil {
+		t.Fatalf("expected the created CLB to be adopted, got %v", err)
+	}
+	if _, err := cloud.getLoadBalancer(context.Background(), cloud.getLoadBalancerName(context.Background(), "kubernetes", service), service); err != nil {
+		t.Errorf("expected adopted CLB, got %v", err)
+	}
+
+	// the adopted CLB is waited on before its listeners are configured
+	if fake.callCount("DescribeTaskStatus") != 1 {
+		t.Errorf("expected the create task of the adopted CLB to be waited on, got %d polls", fake.callCount("DescribeTaskStatus"))
+	}
+
+	// a retried create with the same client token must not duplicate the CLB
+	if err := cloud.createLoadBalancer(context.Background(), "kubernetes", service); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fake.loadBalancers) != 1 {
+		t.Errorf("expected 1 CLB, got %d", len(fake.loadBalancers))
+	}
+}
+
+func TestCreateLoadBalancerClientTokenRenewalsRunOut(t *testing.T) {
+	fake := newFakeCLB()
+	cloud := newTestCloud(fake, newFakeCVM())
+	service := newTestService()
+	// every renewed token returns a deleted CLB
+	token := getLoadBalancerClientToken(service, ClbLoadBalancerTypePublic, nil)
+	for i := 0; i <= loadBalancerClientTokenMaxRenewals; i++ {
+		fake.clientTokens[token] = "lb-deleted"
+		token = renewLoadBalancerClientToken(token, "lb-deleted")
+	}
+
+	if err := cloud.createLoadBalancer(context.Background(), "kubernetes", service); err == nil {
+		t.Errorf("expected an error once the client token renewals run out")
+	}
+	if calls := fake.callCount("CreateLoadBalancer"); calls != loadBalancerClientTokenMaxRenewals+1 {
+		t.Errorf("expected %d creates, got %d", loadBalancerClientTokenMaxRenewals+1, calls)
+	}
+	if fake.callCount("DescribeTaskStatus") != 0 {
+		t.Errorf("expected no wait on the deleted CLB")
+	}
+}
+
+func TestCreateLoadBalancerDoesNotAdoptOnDefiniteFailure(t *testing.T) {
+	fake := newFakeCLB()
+	fake.createLoadBalancerErr = cloudErrors.NewTencentCloudSDKError("InvalidParameter", "bad request", "")
+	cloud := newTestCloud(fake, newFakeCVM())
+
+	if err := cloud.createLoadBalancer(context.Background(), "kubernetes", newTestService()); err == nil {
+		t.Errorf("expected the error to be returned")
+	}
+	if fake.callCount("DescribeLoadBalancers") != 0 {
+		t.Errorf("expected no adoption lookup on a definite failure")
+	}
+}
+
+func newTestNode(name string) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{nodeLabelKeyOfLoadBalancerDefault: nodeLabelValueOfLoadBalancerDefault},
+		},
+	}
+}
+
+// assertLoadBalancerConverged check every service port has a listener with all nodes as targets on the NodePort
+func assertLoadBalancerConverged(t *testing.T, fake *fakeCLB, service *v1.Service, instanceIds []string) {
+	t.Helper()
+	if len(fake.loadBalancers) != 1 {
+		t.Fatalf("expected 1 CLB, got %d", len(fake.loadBalancers))
+	}
+	listeners := fake.listeners[*fake.loadBalancers[0].LoadBalancerId]
+	if len(listeners) != len(service.Spec.Ports) {
+		t.Fatalf("expected %d listeners, got %d", len(service.Spec.Ports), len(listeners))
+	}
+	for _, port := range service.Spec.Ports {
+		var listener *clb.Listener
+		for _, l := range listeners {
+			if *l.Port == int64(port.Port) && *l.Protocol == string(port.Protocol) {
+				listener = l
+			}
+		}
+		if listener == nil {
+			t.Fatalf("missing listener for %s/%d", port.Protocol, port.Port)
+		}
+		if *listener.ListenerName != port.Name {
+			t.Errorf("listener %s/%d name = %s, want %s", port.Protocol, port.Port, *listener.ListenerName, port.Name)
+		}
+		targets := fake.targets[*listener.ListenerId]
+		if len(targets) != len(instanceIds) {
+			t.Errorf("listener %s/%d has %d targets, want %d", port.Protocol, port.Port, len(targets), len(instanceIds))
+		}
+		for _, target := range targets {
+			if *target.Port != int64(port.NodePort) || !containsString(instanceIds, *target.InstanceId) {
+				t.Errorf("unexpected target %s:%d on listener %s/%d", *target.InstanceId, *target.Port, port.Protocol, port.Port)
+			}
+		}
+	}
+}
+
+func TestEnsureLoadBalancerConvergesInOnePass(t *testing.T) {
+	fake := newFakeCLB()
+	cloud := newTestCloud(fake, newFakeCVM(newTestInstance("ins-1", "10.0.1.1"), newTestInstance("ins-2", "10.0.1.2")))
+	nodes := []*v1.Node{newTestNode("10.0.1.1"), newTestNode("10.0.1.2")}
+	service := newTestService()
+	ctx := context.Background()
+
+	// create from scratch
+	status, err := cloud.EnsureLoadBalancer(ctx, "kubernetes", service, nodes)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if len(status.Ingress) != 1 || status.Ingress[0].IP != "10.0.0.1" {
+		t.Errorf("unexpected status %+v", status)
+	}
+	assertLoadBalancerConverged(t, fake, service, []string{"ins-1", "ins-2"})
+
+	// add a port, rename one and change the protocol of another, all within the cache TTL
+	service.Spec.Ports = []v1.ServicePort{
+		{Name: "web", Protocol: v1.ProtocolTCP, Port: 80, NodePort: 30080},
+		{Name: "https", Protocol: v1.ProtocolTCP, Port: 443, NodePort: 30443},
+	}
+	if _, err := cloud.EnsureLoadBalancer(ctx, "kubernetes", service, nodes); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	assertLoadBalancerConverged(t, fake, service, []string{"ins-1", "ins-2"})
+
+	service.Spec.Ports = []v1.ServicePort{
+		{Name: "web", Protocol: v1.ProtocolTCP, Port: 80, NodePort: 30080},
+		{Name: "https", Protocol: v1.ProtocolUDP, Port: 443, NodePort: 30444},
+	}
+	if _, err := cloud.EnsureLoadBalancer(ctx, "kubernetes", service, nodes); err != nil {
+		t.Fatalf("protocol change: %v", err)
+	}
+	assertLoadBalancerConverged(t, fake, service, []string{"ins-1", "ins-2"})
+
+	// in sync, nothing to mutate
+	creates := fake.callCount("CreateListener")
+	if _, err := cloud.EnsureLoadBalancer(ctx, "kubernetes", service, nodes); err != nil {
+		t.Fatalf("resync: %v", err)
+	}
+	if fake.callCount("CreateListener") != creates {
+		t.Errorf("expected no listener created on resync")
+	}
+}
+
+func TestEnsureLoadBalancerAfterDelete(t *testing.T) {
+	fake := newFakeCLB()
+	cloud := newTestCloud(fake, newFakeCVM(newTestInstance("ins-1", "10.0.1.1")))
+	nodes := []*v1.Node{newTestNode("10.0.1.1")}
+	service := newTestService()
+	ctx := context.Background()
+
+	if _, err := cloud.EnsureLoadBalancer(ctx, "kubernetes", service, nodes); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := cloud.EnsureLoadBalancerDeleted(ctx, "kubernetes", service); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, exists, err := cloud.GetLoadBalancer(ctx, "kubernetes", service); err != nil || exists {
+		t.Fatalf("expected deleted CLB to be gone, exists=%v err=%v", exists, err)
+	}
+	// the same service goes back to LoadBalancer type, its client token matches the deleted CLB
+	if _, err := cloud.EnsureLoadBalancer(ctx, "kubernetes", service, nodes); err != nil {
+		t.Fatalf("recreate: %v", err)
+	}
+	assertLoadBalancerConverged(t, fake, service, []string{"ins-1"})
+}
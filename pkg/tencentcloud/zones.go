@@ -0,0 +1,54 @@
+package tencentcloud
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/types"
+	cloudProvider "k8s.io/cloud-provider"
+	"k8s.io/klog"
+)
+
+// GetZone returns the Zone containing the current failure zone and locality region that the program is running in
+func (cloud *Cloud) GetZone(ctx context.Context) (cloudProvider.Zone, error) {
+	klog.V(3).Infof("tencentcloud.GetZone(): entered\n")
+	zone, err := cloud.metadata.Zone(ctx)
+	if err != nil {
+		klog.Warningf("tencentcloud.GetZone: Get error: %v\n", err)
+		klog.V(3).Infof("tencentcloud.GetZone: return: {}, %v\n", err)
+		return cloudProvider.Zone{}, err
+	}
+
+	ret := cloudProvider.Zone{FailureDomain: zone, Region: cloud.txConfig.Region}
+	klog.V(3).Infof("tencentcloud.GetZone: return: %v, nil\n", ret)
+	return ret, nil
+}
+
+// GetZoneByProviderID returns the Zone containing the current zone and locality region of the node specified by providerID
+func (cloud *Cloud) GetZoneByProviderID(ctx context.Context, providerID string) (cloudProvider.Zone, error) {
+	klog.V(3).Infof("tencentcloud.GetZoneByProviderID(\"%s\"): entered\n", providerID)
+	instance, err := cloud.getInstanceByProviderID(ctx, providerID)
+	if err != nil {
+		klog.Warningf("tencentcloud.GetZoneByProviderID: Get error: %v\n", err)
+		klog.V(3).Infof("tencentcloud.GetZoneByProviderID: return: {}, %v\n", err)
+		return cloudProvider.Zone{}, err
+	}
+
+	ret := cloudProvider.Zone{FailureDomain: *instance.Placement.Zone, Region: cloud.txConfig.Region}
+	klog.V(3).Infof("tencentcloud.GetZoneByProviderID: return: %v, nil\n", ret)
+	return ret, nil
+}
+
+// GetZoneByNodeName returns the Zone containing the current zone and locality region of the node specified by node name
+func (cloud *Cloud) GetZoneByNodeName(ctx context.Context, nodeName types.NodeName) (cloudProvider.Zone, error) {
+	klog.V(3).Infof("tencentcloud.GetZoneByNodeName(\"%s\"): entered\n", string(nodeName))
+	instance, err := cloud.getInstanceByRegisteredNodeName(ctx, nodeName)
+	if err != nil {
+		klog.Warningf("tencentcloud.GetZoneByNodeName: Get error: %v\n", err)
+		klog.V(3).Infof("tencentcloud.GetZoneByNodeName: return: {}, %v\n", err)
+		return cloudProvider.Zone{}, err
+	}
+
+	ret := cloudProvider.Zone{FailureDomain: *instance.Placement.Zone, Region: cloud.txConfig.Region}
+	klog.V(3).Infof("tencentcloud.GetZoneByNodeName: return: %v, nil\n", ret)
+	return ret, nil
+}
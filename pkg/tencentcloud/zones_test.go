@@ -0,0 +1,47 @@
+package tencentcloud
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	cloudProvider "k8s.io/cloud-provider"
+)
+
+func TestZones(t *testing.T) {
+	cloud := newTestCloud(newFakeCLB(), newFakeCVM(newTestInstance("ins-1", "10.0.0.1")))
+	expected := cloudProvider.Zone{FailureDomain: "ap-guangzhou-3", Region: "ap-guangzhou"}
+
+	zone, err := cloud.GetZoneByProviderID(context.Background(), "tencentcloud:///ap-guangzhou-3/ins-1")
+	if err != nil || zone != expected {
+		t.Errorf("GetZoneByProviderID = %v, %v, want %v, nil", zone, err, expected)
+	}
+	zone, err = cloud.GetZoneByNodeName(context.Background(), "10.0.0.1")
+	if err != nil || zone != expected {
+		t.Errorf("GetZoneByNodeName = %v, %v, want %v, nil", zone, err, expected)
+	}
+	if _, err := cloud.GetZoneByNodeName(context.Background(), "10.0.0.2"); err != cloudProvider.InstanceNotFound {
+		t.Errorf("GetZoneByNodeName of an unknown node = %v, want InstanceNotFound", err)
+	}
+}
+
+func TestGetZoneFromMetadata(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/latest/meta-data/placement/zone" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("ap-guangzhou-4"))
+	}))
+	defer server.Close()
+	cloud := newTestCloud(newFakeCLB(), newFakeCVM())
+	cloud.metadata = NewMetadataClient(server.URL+"/latest/meta-data/", time.Second)
+
+	zone, err := cloud.GetZone(context.Background())
+	expected := cloudProvider.Zone{FailureDomain: "ap-guangzhou-4", Region: "ap-guangzhou"}
+	if err != nil || zone != expected {
+		t.Errorf("GetZone = %v, %v, want %v, nil", zone, err, expected)
+	}
+}
@@ -0,0 +1,199 @@
+// Package v20170312 is the subset of the tencentcloud vpc 2017-03-12 api used by the cloud provider.
+// It mirrors github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/vpc/v20170312 so that it can be
+// replaced by the sdk module by changing the import path only.
+package v20170312
+
+import (
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	tchttp "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/http"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/profile"
+)
+
+const APIVersion = "2017-03-12"
+
+type Client struct {
+	common.Client
+}
+
+func NewClient(credential common.CredentialIface, region string, clientProfile *profile.ClientProfile) (client *Client, err error) {
+	client = &Client{}
+	client.Init(region).
+		WithCredential(credential).
+		WithProfile(clientProfile)
+	return
+}
+
+func NewDescribeNetworkInterfacesRequest() (request *DescribeNetworkInterfacesRequest) {
+	request = &DescribeNetworkInterfacesRequest{
+		BaseRequest: &tchttp.BaseRequest{},
+	}
+	request.Init().WithApiInfo("vpc", APIVersion, "DescribeNetworkInterfaces")
+	return
+}
+
+func NewDescribeNetworkInterfacesResponse() (response *DescribeNetworkInterfacesResponse) {
+	response = &DescribeNetworkInterfacesResponse{
+		BaseResponse: &tchttp.BaseResponse{},
+	}
+	return
+}
+
+// DescribeNetworkInterfaces queries elastic network interfaces
+func (c *Client) DescribeNetworkInterfaces(request *DescribeNetworkInterfacesRequest) (response *DescribeNetworkInterfacesResponse, err error) {
+	if request == nil {
+		request = NewDescribeNetworkInterfacesRequest()
+	}
+	response = NewDescribeNetworkInterfacesResponse()
+	err = c.Send(request, response)
+	return
+}
+
+func NewDescribeRouteTablesRequest() (request *DescribeRouteTablesRequest) {
+	request = &DescribeRouteTablesRequest{
+		BaseRequest: &tchttp.BaseRequest{},
+	}
+	request.Init().WithApiInfo("vpc", APIVersion, "DescribeRouteTables")
+	return
+}
+
+func NewDescribeRouteTablesResponse() (response *DescribeRouteTablesResponse) {
+	response = &DescribeRouteTablesResponse{
+		BaseResponse: &tchttp.BaseResponse{},
+	}
+	return
+}
+
+// DescribeRouteTables queries route tables and their routes
+func (c *Client) DescribeRouteTables(request *DescribeRouteTablesRequest) (response *DescribeRouteTablesResponse, err error) {
+	if request == nil {
+		request = NewDescribeRouteTablesRequest()
+	}
+	response = NewDescribeRouteTablesResponse()
+	err = c.Send(request, response)
+	return
+}
+
+func NewCreateRoutesRequest() (request *CreateRoutesRequest) {
+	request = &CreateRoutesRequest{
+		BaseRequest: &tchttp.BaseRequest{},
+	}
+	request.Init().WithApiInfo("vpc", APIVersion, "CreateRoutes")
+	return
+}
+
+func NewCreateRoutesResponse() (response *CreateRoutesResponse) {
+	response = &CreateRoutesResponse{
+		BaseResponse: &tchttp.BaseResponse{},
+	}
+	return
+}
+
+// CreateRoutes creates routes in a route table
+func (c *Client) CreateRoutes(request *CreateRoutesRequest) (response *CreateRoutesResponse, err error) {
+	if request == nil {
+		request = NewCreateRoutesRequest()
+	}
+	response = NewCreateRoutesResponse()
+	err = c.Send(request, response)
+	return
+}
+
+func NewDeleteRoutesRequest() (request *DeleteRoutesRequest) {
+	request = &DeleteRoutesRequest{
+		BaseRequest: &tchttp.BaseRequest{},
+	}
+	request.Init().WithApiInfo("vpc", APIVersion, "DeleteRoutes")
+	return
+}
+
+func NewDeleteRoutesResponse() (response *DeleteRoutesResponse) {
+	response = &DeleteRoutesResponse{
+		BaseResponse: &tchttp.BaseResponse{},
+	}
+	return
+}
+
+// DeleteRoutes deletes routes of a route table
+func (c *Client) DeleteRoutes(request *DeleteRoutesRequest) (response *DeleteRoutesResponse, err error) {
+	if request == nil {
+		request = NewDeleteRoutesRequest()
+	}
+	response = NewDeleteRoutesResponse()
+	err = c.Send(request, response)
+	return
+}
+
+func NewReplaceRoutesRequest() (request *ReplaceRoutesRequest) {
+	request = &ReplaceRoutesRequest{
+		BaseRequest: &tchttp.BaseRequest{},
+	}
+	request.Init().WithApiInfo("vpc", APIVersion, "ReplaceRoutes")
+	return
+}
+
+func NewReplaceRoutesResponse() (response *ReplaceRoutesResponse) {
+	response = &ReplaceRoutesResponse{
+		BaseResponse: &tchttp.BaseResponse{},
+	}
+	return
+}
+
+// ReplaceRoutes replaces routes of a route table, matched by RouteId
+func (c *Client) ReplaceRoutes(request *ReplaceRoutesRequest) (response *ReplaceRoutesResponse, err error) {
+	if request == nil {
+		request = NewReplaceRoutesRequest()
+	}
+	response = NewReplaceRoutesResponse()
+	err = c.Send(request, response)
+	return
+}
+
+func NewDescribeVpcsRequest() (request *DescribeVpcsRequest) {
+	request = &DescribeVpcsRequest{
+		BaseRequest: &tchttp.BaseRequest{},
+	}
+	request.Init().WithApiInfo("vpc", APIVersion, "DescribeVpcs")
+	return
+}
+
+func NewDescribeVpcsResponse() (response *DescribeVpcsResponse) {
+	response = &DescribeVpcsResponse{
+		BaseResponse: &tchttp.BaseResponse{},
+	}
+	return
+}
+
+// DescribeVpcs queries vpcs
+func (c *Client) DescribeVpcs(request *DescribeVpcsRequest) (response *DescribeVpcsResponse, err error) {
+	if request == nil {
+		request = NewDescribeVpcsRequest()
+	}
+	response = NewDescribeVpcsResponse()
+	err = c.Send(request, response)
+	return
+}
+
+func NewDescribeSubnetsRequest() (request *DescribeSubnetsRequest) {
+	request = &DescribeSubnetsRequest{
+		BaseRequest: &tchttp.BaseRequest{},
+	}
+	request.Init().WithApiInfo("vpc", APIVersion, "DescribeSubnets")
+	return
+}
+
+func NewDescribeSubnetsResponse() (response *DescribeSubnetsResponse) {
+	response = &DescribeSubnetsResponse{
+		BaseResponse: &tchttp.BaseResponse{},
+	}
+	return
+}
+
+// DescribeSubnets queries subnets
+func (c *Client) DescribeSubnets(request *DescribeSubnetsRequest) (response *DescribeSubnetsResponse, err error) {
+	if request == nil {
+		request = NewDescribeSubnetsRequest()
+	}
+	response = NewDescribeSubnetsResponse()
+	err = c.Send(request, response)
+	return
+}
@@ -0,0 +1,518 @@
+package v20170312
+
+import (
+	"encoding/json"
+
+	tchttp "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/http"
+)
+
+type Filter struct {
+
+	// 属性名称, 若存在多个Filter时，Filter间的关系为逻辑与（AND）关系。
+	Name *string `json:"Name,omitempty" name:"Name"`
+
+	// 属性值, 若同一个Filter存在多个Values，同一Filter下Values间的关系为逻辑或（OR）关系。
+	Values []*string `json:"Values,omitempty" name:"Values"`
+}
+
+type DescribeNetworkInterfacesRequest struct {
+	*tchttp.BaseRequest
+
+	// 弹性网卡实例ID查询。形如：eni-pxir56ns。每次请求的实例的上限为100。参数不支持同时指定NetworkInterfaceIds和Filters。
+	NetworkInterfaceIds []*string `json:"NetworkInterfaceIds,omitempty" name:"NetworkInterfaceIds"`
+
+	// 过滤条件，参数不支持同时指定NetworkInterfaceIds和Filters。
+	// <li>vpc-id - String - （过滤条件）VPC实例ID，形如：vpc-f49l6u0z。</li>
+	// <li>subnet-id - String - （过滤条件）所属子网实例ID，形如：subnet-f49l6u0z。</li>
+	// <li>network-interface-id - String - （过滤条件）弹性网卡实例ID，形如：eni-5k56k7k7。</li>
+	// <li>attachment.instance-id - String - （过滤条件）绑定的云服务器实例ID，形如：ins-3nqpdn3i。</li>
+	// <li>is-primary - Boolean - 是否必填：否 - （过滤条件）按照是否主网卡进行过滤。</li>
+	Filters []*Filter `json:"Filters,omitempty" name:"Filters"`
+
+	// 偏移量，默认为0。
+	Offset *uint64 `json:"Offset,omitempty" name:"Offset"`
+
+	// 返回数量，默认为20，最大值为100。
+	Limit *uint64 `json:"Limit,omitempty" name:"Limit"`
+}
+
+func (r *DescribeNetworkInterfacesRequest) ToJsonString() string {
+	b, _ := json.Marshal(r)
+	return string(b)
+}
+
+func (r *DescribeNetworkInterfacesRequest) FromJsonString(s string) error {
+	return json.Unmarshal([]byte(s), &r)
+}
+
+type DescribeNetworkInterfacesResponse struct {
+	*tchttp.BaseResponse
+	Response *struct {
+
+		// 实例详细信息列表。
+		NetworkInterfaceSet []*NetworkInterface `json:"NetworkInterfaceSet,omitempty" name:"NetworkInterfaceSet"`
+
+		// 符合条件的实例数量。
+		TotalCount *uint64 `json:"TotalCount,omitempty" name:"TotalCount"`
+
+		// 唯一请求 ID，每次请求都会返回。定位问题时需要提供该次请求的 RequestId。
+		RequestId *string `json:"RequestId,omitempty" name:"RequestId"`
+	} `json:"Response"`
+}
+
+func (r *DescribeNetworkInterfacesResponse) ToJsonString() string {
+	b, _ := json.Marshal(r)
+	return string(b)
+}
+
+func (r *DescribeNetworkInterfacesResponse) FromJsonString(s string) error {
+	return json.Unmarshal([]byte(s), &r)
+}
+
+type NetworkInterface struct {
+
+	// 弹性网卡实例ID，例如：eni-f1xjkw1b。
+	NetworkInterfaceId *string `json:"NetworkInterfaceId,omitempty" name:"NetworkInterfaceId"`
+
+	// VPC实例`ID`。
+	VpcId *string `json:"VpcId,omitempty" name:"VpcId"`
+
+	// 子网实例`ID`。
+	SubnetId *string `json:"SubnetId,omitempty" name:"SubnetId"`
+
+	// 是否是主网卡。
+	Primary *bool `json:"Primary,omitempty" name:"Primary"`
+
+	// 内网IP信息。
+	PrivateIpAddressSet []*PrivateIpAddressSpecification `json:"PrivateIpAddressSet,omitempty" name:"PrivateIpAddressSet"`
+
+	// 绑定的云服务器对象。
+	Attachment *NetworkInterfaceAttachment `json:"Attachment,omitempty" name:"Attachment"`
+
+	// `IPv6`地址列表。
+	Ipv6AddressSet []*Ipv6Address `json:"Ipv6AddressSet,omitempty" name:"Ipv6AddressSet"`
+}
+
+type PrivateIpAddressSpecification struct {
+
+	// 内网IP地址。
+	PrivateIpAddress *string `json:"PrivateIpAddress,omitempty" name:"PrivateIpAddress"`
+
+	// 是否是主IP。
+	Primary *bool `json:"Primary,omitempty" name:"Primary"`
+
+	// 公网IP地址。
+	PublicIpAddress *string `json:"PublicIpAddress,omitempty" name:"PublicIpAddress"`
+}
+
+type NetworkInterfaceAttachment struct {
+
+	// 云主机实例ID。
+	InstanceId *string `json:"InstanceId,omitempty" name:"InstanceId"`
+
+	// 网卡在云主机实例内的序号。
+	DeviceIndex *uint64 `json:"DeviceIndex,omitempty" name:"DeviceIndex"`
+}
+
+type Ipv6Address struct {
+
+	// `IPv6`地址，形如：`3402:4e00:20:100:0:8cd9:2a67:71f3`
+	Address *string `json:"Address,omitempty" name:"Address"`
+
+	// 是否是主`IP`。
+	Primary *bool `json:"Primary,omitempty" name:"Primary"`
+}
+
+type DescribeRouteTablesRequest struct {
+	*tchttp.BaseRequest
+
+	// 路由表实例ID，例如：rtb-azd4dt1c。
+	RouteTableIds []*string `json:"RouteTableIds,omitempty" name:"RouteTableIds"`
+
+	// 过滤条件，参数不支持同时指定RouteTableIds和Filters。
+	// <li>route-table-id - String - （过滤条件）路由表实例ID。</li>
+	// <li>route-table-name - String - （过滤条件）路由表名称。</li>
+	// <li>vpc-id - String - （过滤条件）VPC实例ID，形如：vpc-f49l6u0z。</li>
+	Filters []*Filter `json:"Filters,omitempty" name:"Filters"`
+
+	// 偏移量。
+	Offset *string `json:"Offset,omitempty" name:"Offset"`
+
+	// 请求对象个数。
+	Limit *string `json:"Limit,omitempty" name:"Limit"`
+}
+
+func (r *DescribeRouteTablesRequest) ToJsonString() string {
+	b, _ := json.Marshal(r)
+	return string(b)
+}
+
+func (r *DescribeRouteTablesRequest) FromJsonString(s string) error {
+	return json.Unmarshal([]byte(s), &r)
+}
+
+type DescribeRouteTablesResponse struct {
+	*tchttp.BaseResponse
+	Response *struct {
+
+		// 符合条件的实例数量。
+		TotalCount *uint64 `json:"TotalCount,omitempty" name:"TotalCount"`
+
+		// 路由表对象。
+		RouteTableSet []*RouteTable `json:"RouteTableSet,omitempty" name:"RouteTableSet"`
+
+		// 唯一请求 ID，每次请求都会返回。定位问题时需要提供该次请求的 RequestId。
+		RequestId *string `json:"RequestId,omitempty" name:"RequestId"`
+	} `json:"Response"`
+}
+
+func (r *DescribeRouteTablesResponse) ToJsonString() string {
+	b, _ := json.Marshal(r)
+	return string(b)
+}
+
+func (r *DescribeRouteTablesResponse) FromJsonString(s string) error {
+	return json.Unmarshal([]byte(s), &r)
+}
+
+type CreateRoutesRequest struct {
+	*tchttp.BaseRequest
+
+	// 路由表实例ID。
+	RouteTableId *string `json:"RouteTableId,omitempty" name:"RouteTableId"`
+
+	// 路由策略对象。
+	Routes []*Route `json:"Routes,omitempty" name:"Routes"`
+}
+
+func (r *CreateRoutesRequest) ToJsonString() string {
+	b, _ := json.Marshal(r)
+	return string(b)
+}
+
+func (r *CreateRoutesRequest) FromJsonString(s string) error {
+	return json.Unmarshal([]byte(s), &r)
+}
+
+type CreateRoutesResponse struct {
+	*tchttp.BaseResponse
+	Response *struct {
+
+		// 新增的实例个数。
+		TotalCount *uint64 `json:"TotalCount,omitempty" name:"TotalCount"`
+
+		// 路由表对象。
+		RouteTableSet []*RouteTable `json:"RouteTableSet,omitempty" name:"RouteTableSet"`
+
+		// 唯一请求 ID，每次请求都会返回。定位问题时需要提供该次请求的 RequestId。
+		RequestId *string `json:"RequestId,omitempty" name:"RequestId"`
+	} `json:"Response"`
+}
+
+func (r *CreateRoutesResponse) ToJsonString() string {
+	b, _ := json.Marshal(r)
+	return string(b)
+}
+
+func (r *CreateRoutesResponse) FromJsonString(s string) error {
+	return json.Unmarshal([]byte(s), &r)
+}
+
+type DeleteRoutesRequest struct {
+	*tchttp.BaseRequest
+
+	// 路由表实例ID。
+	RouteTableId *string `json:"RouteTableId,omitempty" name:"RouteTableId"`
+
+	// 路由策略对象，删除路由策略时，仅需使用Route的RouteId字段。
+	Routes []*Route `json:"Routes,omitempty" name:"Routes"`
+}
+
+func (r *DeleteRoutesRequest) ToJsonString() string {
+	b, _ := json.Marshal(r)
+	return string(b)
+}
+
+func (r *DeleteRoutesRequest) FromJsonString(s string) error {
+	return json.Unmarshal([]byte(s), &r)
+}
+
+type DeleteRoutesResponse struct {
+	*tchttp.BaseResponse
+	Response *struct {
+
+		// 已删除的路由策略详情。
+		RouteSet []*Route `json:"RouteSet,omitempty" name:"RouteSet"`
+
+		// 唯一请求 ID，每次请求都会返回。定位问题时需要提供该次请求的 RequestId。
+		RequestId *string `json:"RequestId,omitempty" name:"RequestId"`
+	} `json:"Response"`
+}
+
+func (r *DeleteRoutesResponse) ToJsonString() string {
+	b, _ := json.Marshal(r)
+	return string(b)
+}
+
+func (r *DeleteRoutesResponse) FromJsonString(s string) error {
+	return json.Unmarshal([]byte(s), &r)
+}
+
+type ReplaceRoutesRequest struct {
+	*tchttp.BaseRequest
+
+	// 路由表实例ID，例如：rtb-azd4dt1c。
+	RouteTableId *string `json:"RouteTableId,omitempty" name:"RouteTableId"`
+
+	// 路由策略对象。需要指定路由策略ID（RouteId）。
+	Routes []*Route `json:"Routes,omitempty" name:"Routes"`
+}
+
+func (r *ReplaceRoutesRequest) ToJsonString() string {
+	b, _ := json.Marshal(r)
+	return string(b)
+}
+
+func (r *ReplaceRoutesRequest) FromJsonString(s string) error {
+	return json.Unmarshal([]byte(s), &r)
+}
+
+type ReplaceRoutesResponse struct {
+	*tchttp.BaseResponse
+	Response *struct {
+
+		// 原路由策略信息。
+		OldRouteSet []*Route `json:"OldRouteSet,omitempty" name:"OldRouteSet"`
+
+		// 修改后的路由策略信息。
+		NewRouteSet []*Route `json:"NewRouteSet,omitempty" name:"NewRouteSet"`
+
+		// 唯一请求 ID，每次请求都会返回。定位问题时需要提供该次请求的 RequestId。
+		RequestId *string `json:"RequestId,omitempty" name:"RequestId"`
+	} `json:"Response"`
+}
+
+func (r *ReplaceRoutesResponse) ToJsonString() string {
+	b, _ := json.Marshal(r)
+	return string(b)
+}
+
+func (r *ReplaceRoutesResponse) FromJsonString(s string) error {
+	return json.Unmarshal([]byte(s), &r)
+}
+
+type RouteTable struct {
+
+	// VPC实例ID。
+	VpcId *string `json:"VpcId,omitempty" name:"VpcId"`
+
+	// 路由表实例ID，例如：rtb-azd4dt1c。
+	RouteTableId *string `json:"RouteTableId,omitempty" name:"RouteTableId"`
+
+	// 路由表名称。
+	RouteTableName *string `json:"RouteTableName,omitempty" name:"RouteTableName"`
+
+	// 路由表策略集合。
+	RouteSet []*Route `json:"RouteSet,omitempty" name:"RouteSet"`
+
+	// 是否默认路由表。
+	Main *bool `json:"Main,omitempty" name:"Main"`
+}
+
+type Route struct {
+
+	// 目的网段，取值不能在私有网络网段内，例如：112.20.51.0/24。
+	DestinationCidrBlock *string `json:"DestinationCidrBlock,omitempty" name:"DestinationCidrBlock"`
+
+	// 下一跳类型，目前我们支持的类型有：
+	// CVM：公网网关类型的云服务器；
+	// VPN：VPN网关；
+	// DIRECTCONNECT：专线网关；
+	// PEERCONNECTION：对等连接；
+	// HAVIP：高可用虚拟IP；
+	// NAT：NAT网关;
+	// NORMAL_CVM：普通云服务器；
+	// EIP：云服务器的公网IP；
+	// LOCAL_GATEWAY：本地网关。
+	GatewayType *string `json:"GatewayType,omitempty" name:"GatewayType"`
+
+	// 下一跳地址，这里只需要指定不同下一跳类型的网关ID，系统会自动匹配到下一跳地址。
+	// 特别注意：当 GatewayType 为 EIP 时，GatewayId 固定值 '0'
+	GatewayId *string `json:"GatewayId,omitempty" name:"GatewayId"`
+
+	// 路由策略ID。IPv4路由策略ID是有意义的值，IPv6路由策略是无意义的值0。后续建议完全使用字符串唯一ID `RouteItemId`操作路由策略。
+	RouteId *uint64 `json:"RouteId,omitempty" name:"RouteId"`
+
+	// 路由策略描述。
+	RouteDescription *string `json:"RouteDescription,omitempty" name:"RouteDescription"`
+
+	// 是否启用
+	Enabled *bool `json:"Enabled,omitempty" name:"Enabled"`
+
+	// 路由类型，目前我们支持的类型有：
+	// USER：用户路由；
+	// NETD：网络探测路由，创建网络探测实例时，系统默认下发，不可编辑与删除；
+	// CCN：云联网路由，系统默认下发，不可编辑与删除。
+	RouteType *string `json:"RouteType,omitempty" name:"RouteType"`
+
+	// 路由表实例ID，例如：rtb-azd4dt1c。
+	RouteTableId *string `json:"RouteTableId,omitempty" name:"RouteTableId"`
+
+	// 目的IPv6网段，取值不能在私有网络网段内，例如：2402:4e00:1000:810b::/64。
+	DestinationIpv6CidrBlock *string `json:"DestinationIpv6CidrBlock,omitempty" name:"DestinationIpv6CidrBlock"`
+
+	// 路由唯一策略ID。
+	RouteItemId *string `json:"RouteItemId,omitempty" name:"RouteItemId"`
+}
+
+type DescribeVpcsRequest struct {
+	*tchttp.BaseRequest
+
+	// VPC实例ID。形如：vpc-f49l6u0z。每次请求的实例的上限为100。参数不支持同时指定VpcIds和Filters。
+	VpcIds []*string `json:"VpcIds,omitempty" name:"VpcIds"`
+
+	// 过滤条件，不支持同时指定VpcIds和Filters参数。
+	Filters []*Filter `json:"Filters,omitempty" name:"Filters"`
+
+	// 偏移量，默认为0。
+	Offset *string `json:"Offset,omitempty" name:"Offset"`
+
+	// 返回数量，默认为20，最大值为100。
+	Limit *string `json:"Limit,omitempty" name:"Limit"`
+}
+
+func (r *DescribeVpcsRequest) ToJsonString() string {
+	b, _ := json.Marshal(r)
+	return string(b)
+}
+
+func (r *DescribeVpcsRequest) FromJsonString(s string) error {
+	return json.Unmarshal([]byte(s), &r)
+}
+
+type DescribeVpcsResponse struct {
+	*tchttp.BaseResponse
+	Response *struct {
+
+		// 符合条件的对象数。
+		TotalCount *uint64 `json:"TotalCount,omitempty" name:"TotalCount"`
+
+		// VPC对象。
+		VpcSet []*Vpc `json:"VpcSet,omitempty" name:"VpcSet"`
+
+		// 唯一请求 ID，每次请求都会返回。定位问题时需要提供该次请求的 RequestId。
+		RequestId *string `json:"RequestId,omitempty" name:"RequestId"`
+	} `json:"Response"`
+}
+
+func (r *DescribeVpcsResponse) ToJsonString() string {
+	b, _ := json.Marshal(r)
+	return string(b)
+}
+
+func (r *DescribeVpcsResponse) FromJsonString(s string) error {
+	return json.Unmarshal([]byte(s), &r)
+}
+
+type DescribeSubnetsRequest struct {
+	*tchttp.BaseRequest
+
+	// 子网实例ID查询。形如：subnet-pxir56ns。每次请求的实例的上限为100。参数不支持同时指定SubnetIds和Filters。
+	SubnetIds []*string `json:"SubnetIds,omitempty" name:"SubnetIds"`
+
+	// 过滤条件，参数不支持同时指定SubnetIds和Filters。
+	// <li>subnet-id - String - （过滤条件）Subnet实例名称。</li>
+	// <li>vpc-id - String - （过滤条件）VPC实例ID，形如：vpc-f49l6u0z。</li>
+	// <li>cidr-block - String - （过滤条件）子网网段，形如: 192.168.1.0 。</li>
+	Filters []*Filter `json:"Filters,omitempty" name:"Filters"`
+
+	// 偏移量，默认为0。
+	Offset *string `json:"Offset,omitempty" name:"Offset"`
+
+	// 返回数量，默认为20，最大值为100。
+	Limit *string `json:"Limit,omitempty" name:"Limit"`
+}
+
+func (r *DescribeSubnetsRequest) ToJsonString() string {
+	b, _ := json.Marshal(r)
+	return string(b)
+}
+
+func (r *DescribeSubnetsRequest) FromJsonString(s string) error {
+	return json.Unmarshal([]byte(s), &r)
+}
+
+type DescribeSubnetsResponse struct {
+	*tchttp.BaseResponse
+	Response *struct {
+
+		// 符合条件的实例数量。
+		TotalCount *uint64 `json:"TotalCount,omitempty" name:"TotalCount"`
+
+		// 子网对象。
+		SubnetSet []*Subnet `json:"SubnetSet,omitempty" name:"SubnetSet"`
+
+		// 唯一请求 ID，每次请求都会返回。定位问题时需要提供该次请求的 RequestId。
+		RequestId *string `json:"RequestId,omitempty" name:"RequestId"`
+	} `json:"Response"`
+}
+
+func (r *DescribeSubnetsResponse) ToJsonString() string {
+	b, _ := json.Marshal(r)
+	return string(b)
+}
+
+func (r *DescribeSubnetsResponse) FromJsonString(s string) error {
+	return json.Unmarshal([]byte(s), &r)
+}
+
+type Vpc struct {
+
+	// `VPC`名称。
+	VpcName *string `json:"VpcName,omitempty" name:"VpcName"`
+
+	// `VPC`实例`ID`，例如：vpc-azd4dt1c。
+	VpcId *string `json:"VpcId,omitempty" name:"VpcId"`
+
+	// `VPC`的`IPv4` `CIDR`。
+	CidrBlock *string `json:"CidrBlock,omitempty" name:"CidrBlock"`
+
+	// `VPC`的`IPv6` `CIDR`。
+	Ipv6CidrBlock *string `json:"Ipv6CidrBlock,omitempty" name:"Ipv6CidrBlock"`
+
+	// 辅助CIDR
+	AssistantCidrSet []*AssistantCidr `json:"AssistantCidrSet,omitempty" name:"AssistantCidrSet"`
+}
+
+type AssistantCidr struct {
+
+	// `VPC`实例`ID`。形如：`vpc-6v2ht8q5`
+	VpcId *string `json:"VpcId,omitempty" name:"VpcId"`
+
+	// 辅助CIDR。形如：`172.16.0.0/16`
+	CidrBlock *string `json:"CidrBlock,omitempty" name:"CidrBlock"`
+
+	// 辅助CIDR类型（0：普通辅助CIDR，1：容器辅助CIDR），默认都是0。
+	AssistantType *int64 `json:"AssistantType,omitempty" name:"AssistantType"`
+}
+
+type Subnet struct {
+
+	// `VPC`实例`ID`。
+	VpcId *string `json:"VpcId,omitempty" name:"VpcId"`
+
+	// 子网实例`ID`，例如：subnet-bthucmmy。
+	SubnetId *string `json:"SubnetId,omitempty" name:"SubnetId"`
+
+	// 子网名称。
+	SubnetName *string `json:"SubnetName,omitempty" name:"SubnetName"`
+
+	// 子网的 `IPv4` `CIDR`。
+	CidrBlock *string `json:"CidrBlock,omitempty" name:"CidrBlock"`
+
+	// 子网的 `IPv6` `CIDR`。
+	Ipv6CidrBlock *string `json:"Ipv6CidrBlock,omitempty" name:"Ipv6CidrBlock"`
+
+	// 可用区。
+	Zone *string `json:"Zone,omitempty" name:"Zone"`
+}